@@ -0,0 +1,305 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultAccount is the account kept directly in appDir. It is the only one
+// until more are added.
+const defaultAccount = "default"
+
+// accountsDir, inside appDir, holds a directory for every further account.
+const accountsDir = "accounts"
+
+// accountCookie remembers the account chosen with the switcher of the web
+// interface.
+const accountCookie = "account"
+
+var (
+	errInvalidAccountName = errors.New("Account names may only contain letters, digits, spaces, '-' and '_'")
+	errAccountExists      = errors.New("An account with that name already exists")
+	errUnknownAccount     = errors.New("There is no account with that name")
+)
+
+// account is one BoardGameGeek user microBadger rotates badges for. Each has
+// its own badges, slots, presets, schedule, storage, login and scheduler.
+type account struct {
+	name       string
+	state      *appState
+	bgg        bggClient
+	store      storage
+	scheduler  *rotationScheduler
+	strategies map[string]selectionStrategy
+	vault      *vaultCredentials
+	// loginReady starts the scheduler after the first login in the web
+	// interface. Later logins find it full and do not wait.
+	loginReady chan bool
+
+	loginMu sync.Mutex
+	// loginSource is where the credentials of the current login came from.
+	// Logging in again after the session expired reads them from it again.
+	loginSource credentialProvider
+	// reloginMu lets one request log in again while the others wait for it.
+	reloginMu sync.Mutex
+	// rotateMu keeps the scheduler and the web interface from rotating the
+	// slots at the same time, which would mix up their rollbacks.
+	rotateMu sync.Mutex
+	// scheduleMu is held from reading the schedule to saving an edit of
+	// it, so concurrent edits cannot lose each other's changes.
+	scheduleMu sync.Mutex
+}
+
+var (
+	accountsMu sync.RWMutex
+	accounts   = map[string]*account{}
+	// accountLoops counts the scheduler loops still running, which have to
+	// stop before the state is saved on shutdown.
+	accountLoops sync.WaitGroup
+)
+
+// accountDir returns the directory of an account's files relative to
+// appDir.
+func accountDir(name string) string {
+	if name == defaultAccount {
+		return ""
+	}
+	return filepath.Join(accountsDir, name)
+}
+
+// validAccountName reports whether name can be used as an account name. It
+// becomes a directory name, so it must not be able to leave appDir.
+func validAccountName(name string) bool {
+	return presetNamePattern.MatchString(name)
+}
+
+// newAccount opens the storage of the named account and sets it up with the
+// flags.
+func newAccount(name string) (*account, error) {
+	dir := accountDir(name)
+	a := &account{
+		name:       name,
+		state:      newAppState(name),
+		strategies: newStrategies(strategySeed()),
+		vault:      &vaultCredentials{dir: dir},
+		loginReady: make(chan bool, 1),
+	}
+	a.bgg = reloginBGG{bggClient: &httpBGG{}, account: a}
+	a.scheduler = newRotationScheduler(a, realClock{}, strategySeed())
+	a.state.setSlotCount(*slotCount)
+	a.state.setInterval(*interval)
+	if name == *accountName {
+		a.state.setUsername(*username)
+	}
+	openedStore, err := openStorage(*storageName, dir, a.state.notify)
+	if err != nil {
+		return nil, err
+	}
+	a.store = openedStore
+	return a, nil
+}
+
+// strategySeed returns -seed, or the current time when it is not set.
+func strategySeed() int64 {
+	if *seed != 0 {
+		return *seed
+	}
+	return time.Now().UnixNano()
+}
+
+// openAccounts opens the default account and every account in accountsDir.
+func openAccounts() error {
+	names := []string{defaultAccount}
+	dirs, err := ioutil.ReadDir(filepath.Join(appDir, accountsDir))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, dir := range dirs {
+		if dir.IsDir() && validAccountName(dir.Name()) && dir.Name() != defaultAccount {
+			names = append(names, dir.Name())
+		}
+	}
+	for _, name := range names {
+		a, err := newAccount(name)
+		if err != nil {
+			return errors.New("account " + name + ": " + err.Error())
+		}
+		accountsMu.Lock()
+		accounts[name] = a
+		accountsMu.Unlock()
+	}
+	return nil
+}
+
+// addAccount creates the directory of a new account and opens it.
+func addAccount(name string) (*account, error) {
+	if !validAccountName(name) {
+		return nil, errInvalidAccountName
+	}
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+	if _, ok := accounts[name]; ok {
+		return nil, errAccountExists
+	}
+	err := os.MkdirAll(filepath.Join(appDir, accountDir(name)), os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+	a, err := newAccount(name)
+	if err != nil {
+		return nil, err
+	}
+	accounts[name] = a
+	return a, nil
+}
+
+// getAccount returns the named account.
+func getAccount(name string) (*account, bool) {
+	accountsMu.RLock()
+	defer accountsMu.RUnlock()
+	a, ok := accounts[name]
+	return a, ok
+}
+
+// accountNames returns the names of the accounts, the default one first.
+func accountNames() []string {
+	accountsMu.RLock()
+	defer accountsMu.RUnlock()
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		if name != defaultAccount {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{defaultAccount}, names...)
+}
+
+// allAccounts returns every account in the order of accountNames.
+func allAccounts() []*account {
+	list := []*account{}
+	for _, name := range accountNames() {
+		if a, ok := getAccount(name); ok {
+			list = append(list, a)
+		}
+	}
+	return list
+}
+
+// closeAccounts closes the storage of every account and returns the first
+// error.
+func closeAccounts() error {
+	var firstErr error
+	for _, a := range allAccounts() {
+		if a.store == nil {
+			continue
+		}
+		if err := a.store.Close(); err != nil && firstErr == nil {
+			firstErr = errors.New("account " + a.name + ": " + err.Error())
+		}
+	}
+	return firstErr
+}
+
+// requestAccount returns the account a web request is for: the one named by
+// the account query parameter, else the one chosen with the switcher, else
+// the default account.
+func requestAccount(r *http.Request) *account {
+	if a, ok := getAccount(r.URL.Query().Get("account")); ok {
+		return a
+	}
+	if cookie, err := r.Cookie(accountCookie); err == nil {
+		if a, ok := getAccount(cookie.Value); ok {
+			return a
+		}
+	}
+	a, _ := getAccount(defaultAccount)
+	return a
+}
+
+func (a *account) setLoginSource(source credentialProvider) {
+	a.loginMu.Lock()
+	defer a.loginMu.Unlock()
+	a.loginSource = source
+}
+
+func (a *account) currentLoginSource() credentialProvider {
+	a.loginMu.Lock()
+	defer a.loginMu.Unlock()
+	return a.loginSource
+}
+
+// startWeb logs the account in with its saved session, or with its
+// credentials when it has some, and runs its scheduler from the first login
+// until microBadger shuts down.
+func (a *account) startWeb() {
+	source := a.availableCredentials()
+	if a.restoreSession() {
+		a.setLoginSource(source)
+		a.state.notify("Logged in as " + a.state.Username() + " with the session saved by the last run")
+		a.loginReady <- true
+	} else if source != nil {
+		go func() {
+			if err := a.loginWith(source); err != nil {
+				a.state.notify("Login with the credentials from " + source.Name() + " failed: " + err.Error())
+				return
+			}
+			a.state.notify("Logged in as " + a.state.Username() + " with the credentials from " + source.Name())
+			select {
+			case a.loginReady <- true:
+			default:
+			}
+		}()
+	}
+	accountLoops.Add(1)
+	go func() {
+		defer accountLoops.Done()
+		select {
+		case <-a.loginReady:
+			a.scheduler.run(appContext)
+		case <-appContext.Done():
+		}
+	}()
+}
+
+func accountHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if _, ok := getAccount(name); !ok {
+		http.Error(w, errUnknownAccount.Error(), http.StatusNotFound)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: accountCookie, Value: name, Path: "/"})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func accountAddHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	name := r.Form.Get("account-name")
+	a, err := addAccount(name)
+	if err == errInvalidAccountName || err == errAccountExists {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error adding the account: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	a.loadState()
+	a.startWeb()
+	http.SetCookie(w, &http.Cookie{Name: accountCookie, Value: name, Path: "/"})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// loadState loads the selections, rotation history and schedule of the
+// account.
+func (a *account) loadState() {
+	a.loadSelections()
+	a.loadHistory()
+	a.loadSchedule()
+}
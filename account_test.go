@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+// newTestAccount sets up the default account with file storage in a fresh
+// appDir, talking to client, and makes it the only account.
+func newTestAccount(t *testing.T, client bggClient) *account {
+	t.Helper()
+	appDir = t.TempDir()
+	return reopenAccount(t, client)
+}
+
+// reopenAccount opens the default account again in the current appDir, as
+// the next run of microBadger would.
+func reopenAccount(t *testing.T, client bggClient) *account {
+	t.Helper()
+	a, err := newAccount(defaultAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.strategies = newStrategies(1)
+	a.bgg = reloginBGG{bggClient: client, account: a}
+	accountsMu.Lock()
+	accounts = map[string]*account{defaultAccount: a}
+	accountsMu.Unlock()
+	t.Cleanup(func() { a.store.Close() })
+	return a
+}
@@ -0,0 +1,518 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiPrefix is the root of the versioned JSON API. openAPISpec describes
+// every endpoint below it.
+const apiPrefix = "/api/v1/"
+
+type apiError struct {
+	Error string
+}
+
+type apiSlot struct {
+	Id            string
+	Strategy      string
+	AssignedBadge string
+	Badges        []string
+	Weights       map[string]float64
+}
+
+// apiSlotUpdate replaces a slot's selection. Weights and Strategy are
+// optional; badges without a weight keep their current one.
+type apiSlotUpdate struct {
+	Badges   []string
+	Weights  map[string]float64
+	Strategy string
+}
+
+type apiPreset struct {
+	Name  string
+	Slots map[string][]string `json:",omitempty"`
+}
+
+// apiAccount is an account and the state of its BoardGameGeek session.
+type apiAccount struct {
+	Name    string
+	Session sessionStatus
+}
+
+type apiInterval struct {
+	Minutes int
+}
+
+// apiScheduler is the randomization interval and the preset cycle.
+// NextRandomization is ignored on PUT.
+type apiScheduler struct {
+	IntervalMinutes   int
+	Presets           []string
+	NextRandomization *time.Time `json:",omitempty"`
+}
+
+// apiSchedule is the calendar schedule. Only Entries is read on PUT.
+type apiSchedule struct {
+	Entries  schedule
+	Active   string
+	Upcoming []scheduleChange
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, apiError{Error: message})
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "Invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// apiHandler routes every request below apiPrefix. Requests are for the
+// account named by the account query parameter, else the default account.
+func apiHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, apiPrefix), "/")
+	parts := strings.Split(path, "/")
+	name := r.URL.Query().Get("account")
+	if name == "" {
+		name = defaultAccount
+	}
+	a, ok := getAccount(name)
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "Unknown account "+name)
+		return
+	}
+	switch {
+	case path == "openapi.json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(openAPISpec))
+	case path == "accounts":
+		apiAccountsHandler(w, r)
+	case path == "badges":
+		apiBadgesHandler(w, r, a)
+	case path == "categories":
+		apiCategoriesHandler(w, r, a)
+	case path == "slots":
+		apiSlotsHandler(w, r, a)
+	case parts[0] == "slots" && len(parts) == 2:
+		apiSlotHandler(w, r, a, parts[1])
+	case path == "presets":
+		apiPresetsHandler(w, r, a)
+	case parts[0] == "presets" && len(parts) == 2:
+		apiPresetHandler(w, r, a, parts[1])
+	case parts[0] == "presets" && len(parts) == 3:
+		apiPresetActionHandler(w, r, a, parts[1], parts[2])
+	case path == "interval":
+		apiIntervalHandler(w, r, a)
+	case path == "scheduler":
+		apiSchedulerHandler(w, r, a)
+	case path == "schedule":
+		apiScheduleHandler(w, r, a)
+	case path == "randomize":
+		apiRandomizeHandler(w, r, a)
+	case path == "session":
+		apiSessionHandler(w, r, a)
+	case path == "traffic":
+		apiTrafficHandler(w, r)
+	default:
+		writeAPIError(w, http.StatusNotFound, "Unknown API endpoint")
+	}
+}
+
+func apiAccountsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		result := []apiAccount{}
+		for _, a := range allAccounts() {
+			result = append(result, apiAccount{Name: a.name, Session: a.state.sessionSnapshot()})
+		}
+		writeJSON(w, http.StatusOK, result)
+	case "POST":
+		var target apiAccount
+		if !decodeJSON(w, r, &target) {
+			return
+		}
+		a, err := addAccount(target.Name)
+		if err == errInvalidAccountName || err == errAccountExists {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		a.loadState()
+		a.startWeb()
+		writeJSON(w, http.StatusCreated, apiAccount{Name: a.name, Session: a.state.sessionSnapshot()})
+	default:
+		methodNotAllowed(w, "GET", "POST")
+	}
+}
+
+func apiBadgesHandler(w http.ResponseWriter, r *http.Request, a *account) {
+	if r.Method != "GET" {
+		methodNotAllowed(w, "GET")
+		return
+	}
+	badges := a.state.badgesSnapshot()
+	badgeList := make([]*microBadge, 0, len(badges))
+	for _, mb := range badges {
+		badgeList = append(badgeList, mb)
+	}
+	sort.Slice(badgeList, func(i, j int) bool {
+		return badgeList[i].Id < badgeList[j].Id
+	})
+	writeJSON(w, http.StatusOK, badgeList)
+}
+
+func apiCategoriesHandler(w http.ResponseWriter, r *http.Request, a *account) {
+	if r.Method != "GET" {
+		methodNotAllowed(w, "GET")
+		return
+	}
+	categories := map[string][]string{}
+	for category, badges := range a.state.categoriesSnapshot() {
+		ids := make([]string, len(badges))
+		for i, mb := range badges {
+			ids[i] = mb.Id
+		}
+		categories[category] = ids
+	}
+	writeJSON(w, http.StatusOK, categories)
+}
+
+// currentSlot describes a slot as the API reports it.
+func currentSlot(a *account, slotID string, slots map[string]slot, badges map[string]*microBadge) apiSlot {
+	slotIndex, _ := strconv.Atoi(slotID)
+	result := apiSlot{
+		Id:            slotID,
+		Strategy:      a.state.slotStrategy(slotID),
+		AssignedBadge: slots[slotID].AssignedBadge,
+		Badges:        []string{},
+		Weights:       map[string]float64{},
+	}
+	for id, mb := range badges {
+		if mb.IsSelected(slotIndex - 1) {
+			result.Badges = append(result.Badges, id)
+			result.Weights[id] = mb.Weight(slotIndex - 1)
+		}
+	}
+	sort.Strings(result.Badges)
+	return result
+}
+
+func validSlotID(a *account, slotID string) bool {
+	slotNumber, err := strconv.Atoi(slotID)
+	return err == nil && slotNumber >= 1 && slotNumber <= a.state.SlotCount() && strconv.Itoa(slotNumber) == slotID
+}
+
+func apiSlotsHandler(w http.ResponseWriter, r *http.Request, a *account) {
+	if r.Method != "GET" {
+		methodNotAllowed(w, "GET")
+		return
+	}
+	slots := a.state.slotsSnapshot()
+	badges := a.state.badgesSnapshot()
+	result := []apiSlot{}
+	for _, slotID := range a.state.slotIDs() {
+		result = append(result, currentSlot(a, slotID, slots, badges))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func apiSlotHandler(w http.ResponseWriter, r *http.Request, a *account, slotID string) {
+	if !validSlotID(a, slotID) {
+		writeAPIError(w, http.StatusNotFound, "Unknown slot "+slotID)
+		return
+	}
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		var update apiSlotUpdate
+		if !decodeJSON(w, r, &update) {
+			return
+		}
+		badges := a.state.badgesSnapshot()
+		for _, id := range update.Badges {
+			if _, ok := badges[id]; !ok {
+				writeAPIError(w, http.StatusBadRequest, "Unknown badge "+id)
+				return
+			}
+		}
+		for id, weight := range update.Weights {
+			if _, ok := badges[id]; !ok {
+				writeAPIError(w, http.StatusBadRequest, "Unknown badge "+id)
+				return
+			}
+			if !validWeight(weight) {
+				writeAPIError(w, http.StatusBadRequest, "Weights must be finite numbers that are not negative")
+				return
+			}
+		}
+		if update.Strategy != "" && !validStrategy(update.Strategy) {
+			writeAPIError(w, http.StatusBadRequest, "Unknown strategy "+update.Strategy)
+			return
+		}
+
+		slotIndex, _ := strconv.Atoi(slotID)
+		for id, weight := range update.Weights {
+			a.state.setWeight(slotIndex-1, id, weight)
+		}
+		if update.Strategy != "" {
+			a.state.setSlotStrategy(slotID, update.Strategy)
+		}
+		formSlots := a.state.selectedSlots()
+		formSlots[slotID] = update.Badges
+		saveErr := a.submitCheckedMicroBadges(formSlots)
+		if saveErr != nil {
+			writeAPIError(w, http.StatusInternalServerError, saveErr.Error())
+			return
+		}
+	default:
+		methodNotAllowed(w, "GET", "PUT")
+		return
+	}
+	writeJSON(w, http.StatusOK, currentSlot(a, slotID, a.state.slotsSnapshot(), a.state.badgesSnapshot()))
+}
+
+func apiPresetsHandler(w http.ResponseWriter, r *http.Request, a *account) {
+	if r.Method != "GET" {
+		methodNotAllowed(w, "GET")
+		return
+	}
+	presets := []apiPreset{}
+	for _, name := range a.getPresets() {
+		presets = append(presets, apiPreset{Name: name})
+	}
+	writeJSON(w, http.StatusOK, presets)
+}
+
+// writePresetError reports an error from the preset functions.
+func writePresetError(w http.ResponseWriter, err error) {
+	writeAPIError(w, presetErrorStatus(err), presetErrorMessage(err))
+}
+
+func apiPresetHandler(w http.ResponseWriter, r *http.Request, a *account, name string) {
+	// savePreset checks the name of a new preset
+	if !storedPresetName(name) {
+		writePresetError(w, errInvalidPresetName)
+		return
+	}
+	switch r.Method {
+	case "GET":
+		preset, err := a.store.LoadPreset(name)
+		if err != nil {
+			writePresetError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, apiPreset{Name: name, Slots: presetSlots(preset.Badges)})
+	case "PUT":
+		// Saves the current selection under name, like the Save as Preset
+		// button.
+		status := http.StatusOK
+		if !a.presetExists(name) {
+			status = http.StatusCreated
+		}
+		err := a.savePreset(name)
+		if err != nil {
+			writePresetError(w, err)
+			return
+		}
+		writeJSON(w, status, apiPreset{Name: name, Slots: presetSlots(a.state.badgesSnapshot())})
+	case "DELETE":
+		err := a.deletePreset(name)
+		if err != nil {
+			writePresetError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, "GET", "PUT", "DELETE")
+	}
+}
+
+// apiPresetActionHandler serves presets/{name}/rename, duplicate and diff.
+func apiPresetActionHandler(w http.ResponseWriter, r *http.Request, a *account, name, action string) {
+	switch action {
+	case "diff":
+		if r.Method != "GET" {
+			methodNotAllowed(w, "GET")
+			return
+		}
+		if !storedPresetName(name) {
+			writePresetError(w, errInvalidPresetName)
+			return
+		}
+		preset, err := a.store.LoadPreset(name)
+		if err != nil {
+			writePresetError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, a.diffPreset(preset.Badges, a.state.badgesSnapshot()))
+	case "rename", "duplicate":
+		if r.Method != "POST" {
+			methodNotAllowed(w, "POST")
+			return
+		}
+		var target apiPreset
+		if !decodeJSON(w, r, &target) {
+			return
+		}
+		var err error
+		status := http.StatusOK
+		if action == "rename" {
+			err = a.renamePreset(name, target.Name)
+		} else {
+			err = a.duplicatePreset(name, target.Name)
+			status = http.StatusCreated
+		}
+		if err != nil {
+			writePresetError(w, err)
+			return
+		}
+		preset, err := a.store.LoadPreset(target.Name)
+		if err != nil {
+			writePresetError(w, err)
+			return
+		}
+		writeJSON(w, status, apiPreset{Name: target.Name, Slots: presetSlots(preset.Badges)})
+	default:
+		writeAPIError(w, http.StatusNotFound, "Unknown API endpoint")
+	}
+}
+
+func apiIntervalHandler(w http.ResponseWriter, r *http.Request, a *account) {
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		var update apiInterval
+		if !decodeJSON(w, r, &update) {
+			return
+		}
+		if update.Minutes < 1 {
+			writeAPIError(w, http.StatusBadRequest, "Minutes must be at least 1")
+			return
+		}
+		a.scheduler.setInterval(update.Minutes)
+	default:
+		methodNotAllowed(w, "GET", "PUT")
+		return
+	}
+	writeJSON(w, http.StatusOK, apiInterval{Minutes: a.state.Interval()})
+}
+
+func apiSchedulerHandler(w http.ResponseWriter, r *http.Request, a *account) {
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		var update apiScheduler
+		if !decodeJSON(w, r, &update) {
+			return
+		}
+		if update.IntervalMinutes < 1 {
+			writeAPIError(w, http.StatusBadRequest, "IntervalMinutes must be at least 1")
+			return
+		}
+		for _, name := range update.Presets {
+			if !storedPresetName(name) || !a.presetExists(name) {
+				writeAPIError(w, http.StatusBadRequest, "Unknown preset "+name)
+				return
+			}
+		}
+		a.scheduler.setInterval(update.IntervalMinutes)
+		a.startPresetCycle(update.Presets)
+	default:
+		methodNotAllowed(w, "GET", "PUT")
+		return
+	}
+	result := apiScheduler{IntervalMinutes: a.state.Interval(), Presets: a.state.activePresetList()}
+	if next := a.scheduler.nextRandomization(); !next.IsZero() {
+		result.NextRandomization = &next
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func apiScheduleHandler(w http.ResponseWriter, r *http.Request, a *account) {
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		var update apiSchedule
+		if !decodeJSON(w, r, &update) {
+			return
+		}
+		if update.Entries == nil {
+			update.Entries = schedule{}
+		}
+		for _, e := range update.Entries {
+			if storedPresetName(e.Preset) && !a.presetExists(e.Preset) {
+				writeAPIError(w, http.StatusBadRequest, "Unknown preset "+e.Preset)
+				return
+			}
+		}
+		if err := a.updateSchedule(update.Entries); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	default:
+		methodNotAllowed(w, "GET", "PUT")
+		return
+	}
+	now := a.scheduler.clock.Now()
+	currentSchedule := a.state.scheduleSnapshot()
+	result := apiSchedule{Entries: currentSchedule, Upcoming: currentSchedule.upcoming(now, 10)}
+	if active := currentSchedule.activeAt(now); active >= 0 {
+		result.Active = currentSchedule[active].Preset
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func apiSessionHandler(w http.ResponseWriter, r *http.Request, a *account) {
+	if r.Method != "GET" {
+		methodNotAllowed(w, "GET")
+		return
+	}
+	writeJSON(w, http.StatusOK, a.state.sessionSnapshot())
+}
+
+func apiTrafficHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		methodNotAllowed(w, "GET")
+		return
+	}
+	writeJSON(w, http.StatusOK, bggTraffic.snapshot())
+}
+
+func apiRandomizeHandler(w http.ResponseWriter, r *http.Request, a *account) {
+	if r.Method != "POST" {
+		methodNotAllowed(w, "POST")
+		return
+	}
+	results := a.randomizeBadges()
+	status := http.StatusOK
+	for _, v := range results {
+		if !v.Updated {
+			// Some or all slots could not be updated on the site
+			status = http.StatusBadGateway
+		}
+	}
+	writeJSON(w, status, results)
+}
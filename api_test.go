@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// apiRequest sends a request to the API and returns the status and body.
+func apiRequest(method, path, body string) (int, string) {
+	w := httptest.NewRecorder()
+	apiHandler(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return w.Code, w.Body.String()
+}
+
+func TestAPISlotRejectsInvalidWeights(t *testing.T) {
+	a := newTestAccount(t, newFakeBGG(nil))
+	a.state.replaceBadges(map[string]*microBadge{"1": {Id: "1"}})
+	for _, body := range []string{
+		`{"Badges":["1"],"Weights":{"1":-1}}`,
+		`{"Badges":["1"],"Weights":{"1":1e400}}`,
+	} {
+		if code, answer := apiRequest("PUT", "/api/v1/slots/1", body); code != 400 {
+			t.Errorf("%s: got %d %s, want 400", body, code, answer)
+		}
+	}
+	if weight := a.state.badgesSnapshot()["1"].Weight(0); weight != defaultWeight {
+		t.Errorf("the badge weighs %v, want the default", weight)
+	}
+	if code, answer := apiRequest("PUT", "/api/v1/slots/1", `{"Badges":["1"],"Weights":{"1":3}}`); code != 200 {
+		t.Errorf("got %d %s, want 200", code, answer)
+	}
+	if weight := a.state.badgesSnapshot()["1"].Weight(0); weight != 3 {
+		t.Errorf("the badge weighs %v, want 3", weight)
+	}
+}
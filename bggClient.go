@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	website "github.com/allentechnology/website"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// errSessionExpired is returned by a bggClient when BoardGameGeek no longer
+// accepts the login.
+var errSessionExpired = errors.New("The BoardGameGeek login has expired")
+
+// errLoginRejected is returned by Login when BoardGameGeek refuses the
+// username or password, which trying again cannot change.
+var errLoginRejected = errors.New("Login failed")
+
+// sessionCheckPath answers with the user that is logged in.
+const sessionCheckPath = "/api/users/current"
+
+// bggClient is everything microBadger needs from boardgamegeek.com.
+type bggClient interface {
+	Login(username, password string) error
+	// FetchMicrobadges returns the HTML of the user's microbadge page. The
+	// caller closes it.
+	FetchMicrobadges(username string) (io.ReadCloser, error)
+	SetSlot(slotNumber, badgeID string) error
+	ClearSlot(slotNumber string) error
+}
+
+// bggURL joins path onto the configured BoardGameGeek base URL.
+func bggURL(path string) string {
+	return strings.TrimSuffix(*bggBaseURL, "/") + path
+}
+
+// httpBGG talks to the real site over HTTP. Every request goes through
+// bggTraffic.
+type httpBGG struct {
+	mu     sync.Mutex
+	client *http.Client
+}
+
+// session returns the logged in client, or nil before the first login.
+func (b *httpBGG) session() *http.Client {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.client
+}
+
+// Login waits for the rate limiter and the circuit breaker of bggTraffic and
+// counts in its statistics, but website.Login sends it with a client of its
+// own, so it is a single attempt without the retries of bggTraffic. relogin
+// tries again instead.
+func (b *httpBGG) Login(username, password string) error {
+	var client *http.Client
+	err := bggTraffic.do(context.Background(), 1, func(int) error {
+		var err error
+		client, err = website.Login(bggURL("/login"), username, password, bggRequestTimeout())
+		if _, ok := err.(*url.Error); ok {
+			return retryError{err: err}
+		}
+		return err
+	})
+	if err != nil {
+		return loginError(err)
+	}
+	// Each request gets its deadline from bggTraffic
+	client.Transport = bggTraffic
+	client.Timeout = 0
+	b.mu.Lock()
+	b.client = client
+	b.mu.Unlock()
+	return nil
+}
+
+// loginError turns an error of a login attempt into the one Login returns.
+// website reports refused credentials only with the text of
+// errLoginRejected, which TestLoginError pins down.
+func loginError(err error) error {
+	if retry, ok := err.(retryError); ok {
+		return retry.err
+	}
+	if err.Error() == errLoginRejected.Error() {
+		return errLoginRejected
+	}
+	return err
+}
+
+// Cookies returns the cookies of the login, or nil before the first one.
+func (b *httpBGG) Cookies() []sessionCookie {
+	client := b.session()
+	if client == nil || client.Jar == nil {
+		return nil
+	}
+	siteURL, err := url.Parse(bggURL("/"))
+	if err != nil {
+		return nil
+	}
+	cookies := []sessionCookie{}
+	for _, cookie := range client.Jar.Cookies(siteURL) {
+		cookies = append(cookies, sessionCookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return cookies
+}
+
+// RestoreCookies makes the client use a login saved with Cookies.
+func (b *httpBGG) RestoreCookies(cookies []sessionCookie) error {
+	siteURL, err := url.Parse(bggURL("/"))
+	if err != nil {
+		return err
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return err
+	}
+	httpCookies := make([]*http.Cookie, len(cookies))
+	for i, cookie := range cookies {
+		httpCookies[i] = &http.Cookie{Name: cookie.Name, Value: cookie.Value, Path: "/"}
+	}
+	jar.SetCookies(siteURL, httpCookies)
+	b.mu.Lock()
+	b.client = &http.Client{Jar: jar, Transport: bggTraffic}
+	b.mu.Unlock()
+	return nil
+}
+
+// CheckSession asks BoardGameGeek who is logged in, which changes nothing.
+// It returns errSessionExpired when the login is no longer accepted.
+func (b *httpBGG) CheckSession() error {
+	client := b.session()
+	if client == nil {
+		return errSessionExpired
+	}
+	resp, err := client.Get(bggURL(sessionCheckPath))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if loginRequired(resp) {
+		return errSessionExpired
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("BoardGameGeek answered " + resp.Status + " to the session check")
+	}
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(data, &user); err != nil {
+		return errors.New("BoardGameGeek answered the session check with something unexpected: " + err.Error())
+	}
+	if user.Username == "" {
+		return errSessionExpired
+	}
+	return nil
+}
+
+func (b *httpBGG) FetchMicrobadges(username string) (io.ReadCloser, error) {
+	client := b.session()
+	if client == nil {
+		return nil, errors.New("Not logged in")
+	}
+	resp, err := client.Get(bggURL("/user/" + username + "/microbadges"))
+	if err != nil {
+		return nil, err
+	}
+	if loginRequired(resp) {
+		resp.Body.Close()
+		return nil, errSessionExpired
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.New("BoardGameGeek answered " + resp.Status + " for the microbadge page")
+	}
+	return resp.Body, nil
+}
+
+// AssignedSlots reads which badge each slot shows from the profile page of
+// username, so rotations are checked against what BoardGameGeek shows.
+func (b *httpBGG) AssignedSlots(username string) (map[string]string, error) {
+	client := b.session()
+	if client == nil {
+		return nil, errors.New("Not logged in")
+	}
+	resp, err := client.Get(bggURL("/user/" + username))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("BoardGameGeek answered " + resp.Status + " for the profile page")
+	}
+	return parseAssignedSlots(resp.Body)
+}
+
+func (b *httpBGG) SetSlot(slotNumber, badgeID string) error {
+	return b.postSlot(url.Values{
+		"badgeid": {badgeID},
+		"slot":    {slotNumber},
+		"ajax":    {"1"},
+		"action":  {"setslot"},
+	})
+}
+
+func (b *httpBGG) ClearSlot(slotNumber string) error {
+	return b.postSlot(url.Values{
+		"slot":   {slotNumber},
+		"ajax":   {"1"},
+		"action": {"clearslot"},
+	})
+}
+
+func (b *httpBGG) postSlot(form url.Values) error {
+	client := b.session()
+	if client == nil {
+		return errors.New("Not logged in")
+	}
+	resp, err := client.PostForm(bggURL("/geekmicrobadge.php"), form)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	defer resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	return checkSlotAnswer(resp, data)
+}
+
+// loginRequired reports whether BoardGameGeek answered that a request needs
+// a login, by its status or by redirecting it to the login page.
+func loginRequired(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return true
+	}
+	return resp.Request != nil && strings.HasSuffix(resp.Request.URL.Path, "/login")
+}
+
+// asksToLogIn reports whether a message asks to log in.
+func asksToLogIn(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "login") || strings.Contains(message, "log in")
+}
+
+// checkSlotAnswer looks at BoardGameGeek's answer to a slot update. It is
+// JSON with an error message when the update was refused, and asking to log
+// in means the session expired. Older answers are plain text, which only
+// tell a missing login apart.
+func checkSlotAnswer(resp *http.Response, data []byte) error {
+	if loginRequired(resp) {
+		return errSessionExpired
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return errors.New("BoardGameGeek answered " + resp.Status + " to the slot update")
+	}
+	var answer struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &answer) != nil {
+		if asksToLogIn(string(data)) {
+			return errSessionExpired
+		}
+	} else if answer.Error != "" {
+		if asksToLogIn(answer.Error) {
+			return errSessionExpired
+		}
+		return errors.New("BoardGameGeek refused the slot update: " + answer.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("BoardGameGeek answered " + resp.Status + " to the slot update")
+	}
+	return nil
+}
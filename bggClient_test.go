@@ -0,0 +1,262 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestSite points microBadger at a test server run by handler, with a
+// rate limit tests do not notice.
+func newTestSite(t *testing.T, handler http.Handler) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	savedURL, savedTraffic := *bggBaseURL, bggTraffic
+	*bggBaseURL = server.URL
+	bggTraffic = newBGGTransport(http.DefaultTransport, 1000, 1000)
+	t.Cleanup(func() {
+		server.Close()
+		*bggBaseURL, bggTraffic = savedURL, savedTraffic
+	})
+	return server
+}
+
+// testSite is a small BoardGameGeek that knows the login user/pw, accepts
+// slot updates with the shortest possible answer and shows the three slots
+// on the profile page of user.
+func testSite() http.Handler {
+	var mu sync.Mutex
+	sessions := map[string]bool{}
+	slots := map[string]string{}
+	loggedIn := func(r *http.Request) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		cookie, err := r.Cookie("SessionID")
+		return err == nil && sessions[cookie.Value]
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("username") != "user" || r.Form.Get("password") != "pw" {
+			http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		id := fmt.Sprintf("session-%d", len(sessions)+1)
+		sessions[id] = true
+		mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: "SessionID", Value: id, Path: "/"})
+	})
+	mux.HandleFunc(sessionCheckPath, func(w http.ResponseWriter, r *http.Request) {
+		if !loggedIn(r) {
+			http.Error(w, "{}", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"username":"user"}`)
+	})
+	mux.HandleFunc("/geekmicrobadge.php", func(w http.ResponseWriter, r *http.Request) {
+		if !loggedIn(r) {
+			fmt.Fprint(w, `{"error":"You must login to use this feature."}`)
+			return
+		}
+		r.ParseForm()
+		mu.Lock()
+		slots[r.Form.Get("slot")] = r.Form.Get("badgeid")
+		mu.Unlock()
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("/user/user", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprint(w, `<html><body><div class="profile_microbadges">`)
+		for _, slot := range []string{"1", "2", "3"} {
+			if slots[slot] == "" {
+				fmt.Fprint(w, "<span></span>\n")
+				continue
+			}
+			fmt.Fprintf(w, `<span><a href="/microbadge/%s"><img class="mb" /></a></span>`+"\n", slots[slot])
+		}
+		fmt.Fprint(w, `</div></body></html>`)
+	})
+	return mux
+}
+
+func TestCheckSlotAnswer(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		status int
+		path   string
+		answer string
+		want   error
+	}{
+		{"short success", 200, "/geekmicrobadge.php", `{"success":true}`, nil},
+		{"empty success", 200, "/geekmicrobadge.php", ``, nil},
+		{"plain text success", 200, "/geekmicrobadge.php", `Slot updated`, nil},
+		{"JSON login error", 200, "/geekmicrobadge.php", `{"error":"You must login to use this feature."}`, errSessionExpired},
+		{"plain text login error", 200, "/geekmicrobadge.php", `Please log in`, errSessionExpired},
+		{"unauthorized", 401, "/geekmicrobadge.php", ``, errSessionExpired},
+		{"forbidden", 403, "/geekmicrobadge.php", `{}`, errSessionExpired},
+		{"redirected to the login page", 200, "/login", `<html>Sign in</html>`, errSessionExpired},
+		{"refused", 200, "/geekmicrobadge.php", `{"error":"Invalid badge"}`, errors.New("BoardGameGeek refused the slot update: Invalid badge")},
+		{"bad request", 400, "/geekmicrobadge.php", `Bad request`, errors.New("BoardGameGeek answered 400 Bad Request to the slot update")},
+		{"server error", 503, "/geekmicrobadge.php", ``, errors.New("BoardGameGeek answered 503 Service Unavailable to the slot update")},
+	} {
+		resp := &http.Response{
+			StatusCode: test.status,
+			Status:     fmt.Sprintf("%d %s", test.status, http.StatusText(test.status)),
+			Request:    &http.Request{URL: &url.URL{Path: test.path}},
+		}
+		err := checkSlotAnswer(resp, []byte(test.answer))
+		if err != test.want && (err == nil || test.want == nil || err.Error() != test.want.Error()) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestHTTPBGGLogin(t *testing.T) {
+	newTestSite(t, testSite())
+	client := &httpBGG{}
+	if err := client.Login("user", "wrong"); err != errLoginRejected {
+		t.Errorf("a wrong password gave %v, want errLoginRejected", err)
+	}
+	if err := client.SetSlot("1", "1001"); err == nil {
+		t.Error("updated a slot without a login")
+	}
+	if err := client.Login("user", "pw"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CheckSession(); err != nil {
+		t.Errorf("checking a fresh login gave %v", err)
+	}
+	if err := client.SetSlot("1", "1001"); err != nil {
+		t.Errorf("a short answer to a slot update gave %v", err)
+	}
+	if err := client.ClearSlot("1"); err != nil {
+		t.Errorf("clearing a slot gave %v", err)
+	}
+
+	// A login the site no longer knows
+	expired := &httpBGG{}
+	if err := expired.RestoreCookies([]sessionCookie{{Name: "SessionID", Value: "old"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expired.CheckSession(); err != errSessionExpired {
+		t.Errorf("checking an unknown session gave %v, want errSessionExpired", err)
+	}
+	if err := expired.SetSlot("1", "1001"); err != errSessionExpired {
+		t.Errorf("a slot update with an unknown session gave %v, want errSessionExpired", err)
+	}
+}
+
+func TestRestoreSession(t *testing.T) {
+	newTestSite(t, testSite())
+	a := newTestAccount(t, &httpBGG{})
+	if err := a.loginWith(staticCredentials{name: "the test", login: credentials{"user", "pw"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := reopenAccount(t, &httpBGG{})
+	if !restarted.restoreSession() {
+		t.Fatal("a valid saved session was not restored")
+	}
+	if restarted.state.Username() != "user" || restarted.state.sessionSnapshot().State != sessionLoggedIn {
+		t.Errorf("restored %q in state %s", restarted.state.Username(), restarted.state.sessionSnapshot().State)
+	}
+
+	if err := a.store.SaveSession(sessionInfo{Username: "user", Cookies: []sessionCookie{{Name: "SessionID", Value: "old"}}}); err != nil {
+		t.Fatal(err)
+	}
+	stale := reopenAccount(t, &httpBGG{})
+	if stale.restoreSession() {
+		t.Error("an expired saved session was restored")
+	}
+	if stale.state.sessionSnapshot().State == sessionLoggedIn {
+		t.Error("an expired saved session shows as logged in")
+	}
+}
+
+func TestHTTPBGGRotationIsVerified(t *testing.T) {
+	newTestSite(t, testSite())
+	client := &httpBGG{}
+	a := newTestAccount(t, client)
+	setUpRotation(t, a)
+
+	results := a.randomizeBadges()
+	for _, r := range results {
+		if r.Outcome != slotUpdated || !r.Verified {
+			t.Errorf("slot %s: %+v, want it updated and verified", r.Slot, r)
+		}
+	}
+	shown, err := client.AssignedSlots("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if shown[r.Slot] != r.BadgeId {
+			t.Errorf("the profile shows %q in slot %s, want %q", shown[r.Slot], r.Slot, r.BadgeId)
+		}
+	}
+}
+
+func TestHTTPBGGRotationRollsBackUnappliedSlot(t *testing.T) {
+	// The site answers success for slot 3 but never changes it
+	site := testSite()
+	mux := http.NewServeMux()
+	mux.Handle("/", site)
+	mux.HandleFunc("/geekmicrobadge.php", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("slot") == "3" {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		site.ServeHTTP(w, r)
+	})
+	newTestSite(t, mux)
+	client := &httpBGG{}
+	a := newTestAccount(t, client)
+	setUpRotation(t, a)
+
+	results := a.randomizeBadges()
+	want := []string{slotRolledBack, slotRolledBack, slotFailed}
+	for i, r := range results {
+		if r.Outcome != want[i] || !r.Verified {
+			t.Errorf("slot %s: %+v, want %s and verified", r.Slot, r, want[i])
+		}
+	}
+	if !strings.Contains(results[2].Error, "the profile shows no badge instead") {
+		t.Errorf("slot 3: %q", results[2].Error)
+	}
+	shown, err := client.AssignedSlots("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for slot, badgeID := range shown {
+		if badgeID != "" {
+			t.Errorf("the profile shows %q in slot %s after the rollback", badgeID, slot)
+		}
+	}
+}
+
+// TestLoginError pins down the text website uses for refused credentials,
+// the only way Login can tell them from other failures.
+func TestLoginError(t *testing.T) {
+	network := &url.Error{Op: "Post", URL: "https://boardgamegeek.com/login", Err: errors.New("connection refused")}
+	other := errors.New("unexpected answer")
+	for _, test := range []struct {
+		err  error
+		want error
+	}{
+		{errors.New("Login failed"), errLoginRejected},
+		{retryError{err: network}, network},
+		{other, other},
+	} {
+		if got := loginError(test.err); got != test.want {
+			t.Errorf("%v became %v, want %v", test.err, got, test.want)
+		}
+	}
+}
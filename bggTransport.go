@@ -0,0 +1,417 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Retries of a failed BoardGameGeek request wait bggBackoff after the first
+// failure and twice as long after each further one, up to bggMaxBackoff.
+const (
+	bggBackoff    = time.Second
+	bggMaxBackoff = 30 * time.Second
+)
+
+// The circuit breaker opens after breakerThreshold failed requests in a row
+// and lets a trial request through after breakerCooldown.
+const (
+	breakerThreshold = 5
+	breakerCooldown  = 30 * time.Second
+)
+
+// States of the circuit breaker.
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half-open"
+)
+
+// bggTraffic carries every request to BoardGameGeek, for all accounts. main
+// sets it up from the flags.
+var bggTraffic = newBGGTransport(http.DefaultTransport, 1, 2)
+
+// bggStats counts the requests to BoardGameGeek, for the web interface and
+// the API.
+type bggStats struct {
+	// Requests is the number of attempts sent, including retries
+	Requests int
+	Retries  int
+	// GaveUp counts requests that still failed after their last attempt
+	GaveUp int
+	// Throttled counts attempts the rate limiter held back, for
+	// ThrottledSeconds in total
+	Throttled        int
+	ThrottledSeconds float64
+	TooManyRequests  int
+	ServerErrors     int
+	NetworkErrors    int
+	Breaker          string
+	BreakerOpens     int
+	// OpenUntil is when an open breaker lets the next trial request through
+	OpenUntil   *time.Time `json:",omitempty"`
+	LastError   string     `json:",omitempty"`
+	LastErrorAt *time.Time `json:",omitempty"`
+}
+
+// bggTransport is the HTTP layer between microBadger and BoardGameGeek. It
+// spaces requests with a token bucket, gives every attempt its own deadline,
+// retries network errors, 429 and 5xx answers with exponential backoff and
+// jitter, and stops sending requests for a while once too many failed in a
+// row.
+type bggTransport struct {
+	base    http.RoundTripper
+	limiter *tokenBucket
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	stats    bggStats
+	failures int
+	// openUntil is when an open breaker lets the next trial request through,
+	// trial is set while that request runs
+	openUntil time.Time
+	trial     bool
+}
+
+func newBGGTransport(base http.RoundTripper, rate float64, burst int) *bggTransport {
+	return &bggTransport{
+		base:    base,
+		limiter: newTokenBucket(rate, burst),
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		stats:   bggStats{Breaker: breakerClosed},
+	}
+}
+
+// retryError is an attempt that failed in a way a retry may fix: a network
+// error, with status 0, or a 429 or 5xx answer. retryAfter is the wait
+// BoardGameGeek asked for, if any.
+type retryError struct {
+	err        error
+	status     int
+	retryAfter time.Duration
+}
+
+func (r retryError) Error() string {
+	return r.err.Error()
+}
+
+// circuitOpenError is returned without sending a request while the breaker
+// is open.
+type circuitOpenError struct {
+	until time.Time
+}
+
+func (c circuitOpenError) Error() string {
+	return "BoardGameGeek failed " + strconv.Itoa(breakerThreshold) + " requests in a row, so none are sent until " + c.until.Format("15:04:05")
+}
+
+// RoundTrip sends req through the rate limiter and the circuit breaker,
+// retrying it when that may help. The last answer is returned even when it
+// is still a 429 or 5xx.
+func (t *bggTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	attempts := *bggRetries + 1
+	// A body that cannot be sent again allows no retry
+	if req.Body != nil && req.GetBody == nil {
+		attempts = 1
+	}
+	err := t.do(req.Context(), attempts, func(attempt int) error {
+		// The deadline lasts until the body of the answer is closed
+		ctx, cancel := context.WithTimeout(req.Context(), bggRequestTimeout())
+		attemptReq := req.Clone(ctx)
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return err
+			}
+			attemptReq.Body = body
+		}
+		var err error
+		resp, err = t.base.RoundTrip(attemptReq)
+		if err != nil {
+			cancel()
+			if req.Context().Err() != nil {
+				return req.Context().Err()
+			}
+			return retryError{err: err}
+		}
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			resp.Body = cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return nil
+		}
+		statusErr := retryError{err: errors.New("BoardGameGeek answered " + resp.Status), status: resp.StatusCode}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			statusErr.retryAfter = time.Duration(seconds) * time.Second
+		}
+		if attempt < attempts {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+			cancel()
+		} else {
+			resp.Body = cancelBody{ReadCloser: resp.Body, cancel: cancel}
+		}
+		return statusErr
+	})
+	if retry, ok := err.(retryError); ok {
+		if retry.status != 0 {
+			// The caller reads the error answer itself
+			return resp, nil
+		}
+		return nil, retry.err
+	}
+	if err != nil {
+		// An earlier answer was already closed for a retry
+		return nil, err
+	}
+	return resp, nil
+}
+
+// cancelBody ends the deadline of a request when its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelBody) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// bggRequestTimeout is the deadline of one attempt of a request.
+func bggRequestTimeout() time.Duration {
+	return time.Duration(*bggTimeout) * time.Second
+}
+
+// do runs attempt, numbered from 1, up to attempts times. Each attempt
+// first waits for the circuit breaker and the rate limiter.
+func (t *bggTransport) do(ctx context.Context, attempts int, attempt func(attempt int) error) error {
+	for n := 1; ; n++ {
+		if err := t.allow(); err != nil {
+			return err
+		}
+		waited, err := t.limiter.wait(ctx)
+		if err != nil {
+			t.finishTrial()
+			return err
+		}
+		t.count(func(s *bggStats) {
+			s.Requests++
+			if waited > 0 {
+				s.Throttled++
+				s.ThrottledSeconds += waited.Seconds()
+			}
+		})
+
+		err = attempt(n)
+		if ctx.Err() != nil {
+			// Cancelled, which says nothing about BoardGameGeek
+			t.finishTrial()
+			return err
+		}
+		retry, retriable := err.(retryError)
+		t.record(retry, retriable)
+		if !retriable {
+			return err
+		}
+		if n >= attempts {
+			t.count(func(s *bggStats) { s.GaveUp++ })
+			return err
+		}
+
+		delay := t.backoff(n)
+		if retry.retryAfter > delay {
+			delay = retry.retryAfter
+		}
+		if delay > bggMaxBackoff {
+			delay = bggMaxBackoff
+		}
+		t.count(func(s *bggStats) { s.Retries++ })
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// backoff returns the wait before retry number n, between half and all of
+// the doubled delay so that retries from several accounts spread out.
+func (t *bggTransport) backoff(n int) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return backoffDelay(bggBackoff, bggMaxBackoff, n, t.rand)
+}
+
+// backoffDelay returns base doubled for every failure after the first, at
+// most max, varied randomly between half and all of it.
+func backoffDelay(base, max time.Duration, failures int, r *rand.Rand) time.Duration {
+	delay := base
+	for i := 1; i < failures && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay/2 + time.Duration(r.Int63n(int64(delay/2)+1))
+}
+
+// allow reports whether the breaker lets a request through. Once the
+// cooldown of an open breaker has passed, one trial request goes through;
+// requests made while it runs are not sent and get a circuitOpenError, as
+// while the breaker is open.
+func (t *bggTransport) allow() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch t.stats.Breaker {
+	case breakerOpen:
+		if time.Now().Before(t.openUntil) {
+			return circuitOpenError{until: t.openUntil}
+		}
+		t.stats.Breaker = breakerHalfOpen
+		t.trial = true
+		return nil
+	case breakerHalfOpen:
+		if t.trial {
+			return circuitOpenError{until: time.Now().Add(time.Second)}
+		}
+		t.trial = true
+	}
+	return nil
+}
+
+// finishTrial lets another request be the trial when one gave up before it
+// was sent.
+func (t *bggTransport) finishTrial() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.trial = false
+}
+
+// record updates the statistics and the breaker with the outcome of an
+// attempt. Only failures a retry may fix count towards opening the breaker;
+// any other outcome means BoardGameGeek answered.
+func (t *bggTransport) record(retry retryError, retriable bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.trial = false
+	if !retriable {
+		t.failures = 0
+		if t.stats.Breaker != breakerClosed {
+			log.Println("BoardGameGeek answers again, sending requests")
+			t.stats.Breaker = breakerClosed
+			t.stats.OpenUntil = nil
+		}
+		return
+	}
+
+	now := time.Now()
+	t.stats.LastError = redactSecrets(retry.Error())
+	t.stats.LastErrorAt = &now
+	switch {
+	case retry.status == http.StatusTooManyRequests:
+		t.stats.TooManyRequests++
+	case retry.status == 0:
+		t.stats.NetworkErrors++
+	default:
+		t.stats.ServerErrors++
+	}
+
+	t.failures++
+	if t.stats.Breaker == breakerHalfOpen || t.failures >= breakerThreshold {
+		if t.stats.Breaker != breakerOpen {
+			t.stats.BreakerOpens++
+			log.Printf("BoardGameGeek failed %d requests in a row, pausing requests for %s", t.failures, breakerCooldown)
+		}
+		t.stats.Breaker = breakerOpen
+		t.openUntil = now.Add(breakerCooldown)
+		t.stats.OpenUntil = &t.openUntil
+	}
+}
+
+func (t *bggTransport) count(update func(s *bggStats)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	update(&t.stats)
+}
+
+// snapshot returns a copy of the statistics.
+func (t *bggTransport) snapshot() bggStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.stats
+	if stats.OpenUntil != nil {
+		openUntil := *stats.OpenUntil
+		stats.OpenUntil = &openUntil
+	}
+	return stats
+}
+
+// tokenBucket lets burst requests through at once and then rate requests a
+// second. A rate of 0 does not limit.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait takes a token, waiting until there is one, and returns how long it
+// waited.
+func (b *tokenBucket) wait(ctx context.Context) (time.Duration, error) {
+	if b.rate <= 0 {
+		return 0, nil
+	}
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	// Taking the token before it is there queues the requests in order
+	b.tokens--
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+	if delay <= 0 {
+		return 0, nil
+	}
+	select {
+	case <-time.After(delay):
+		return delay, nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return 0, ctx.Err()
+	}
+}
+
+// String describes the statistics in one line for the web interface.
+func (s bggStats) String() string {
+	text := fmt.Sprintf("%d requests, %d retries, %d failed", s.Requests, s.Retries, s.GaveUp)
+	if s.Throttled > 0 {
+		text += fmt.Sprintf(", %d held back by the rate limit for %.0fs", s.Throttled, s.ThrottledSeconds)
+	}
+	if s.TooManyRequests > 0 {
+		text += fmt.Sprintf(", %d answered 429", s.TooManyRequests)
+	}
+	return text
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// roundTripFunc answers requests with a function instead of a network.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func answer(status int, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+	}
+}
+
+// setRetries changes -bgg-retries for one test.
+func setRetries(t *testing.T, retries int) {
+	saved := *bggRetries
+	*bggRetries = retries
+	t.Cleanup(func() { *bggRetries = saved })
+}
+
+func TestTokenBucket(t *testing.T) {
+	unlimited := newTokenBucket(0, 1)
+	for i := 0; i < 10; i++ {
+		if waited, err := unlimited.wait(context.Background()); waited != 0 || err != nil {
+			t.Fatalf("a rate of 0 waited %s, %v", waited, err)
+		}
+	}
+
+	bucket := newTokenBucket(10, 2)
+	for i := 0; i < 2; i++ {
+		if waited, err := bucket.wait(context.Background()); waited != 0 || err != nil {
+			t.Fatalf("request %d of the burst waited %s, %v", i+1, waited, err)
+		}
+	}
+	waited, err := bucket.wait(context.Background())
+	if err != nil || waited < 50*time.Millisecond || waited > 100*time.Millisecond {
+		t.Errorf("the request after the burst waited %s, %v, want about 100ms", waited, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := bucket.wait(ctx); err != context.Canceled {
+		t.Errorf("a cancelled wait returned %v", err)
+	}
+	bucket.mu.Lock()
+	tokens := bucket.tokens
+	bucket.mu.Unlock()
+	if tokens < -0.5 {
+		t.Errorf("a cancelled wait kept its token, %.2f left", tokens)
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for failures := 1; failures <= 8; failures++ {
+		full := time.Second << uint(failures-1)
+		if full > 30*time.Second {
+			full = 30 * time.Second
+		}
+		for i := 0; i < 100; i++ {
+			delay := backoffDelay(time.Second, 30*time.Second, failures, r)
+			if delay < full/2 || delay > full {
+				t.Fatalf("failure %d: waited %s, want between %s and %s", failures, delay, full/2, full)
+			}
+		}
+	}
+}
+
+func TestTransportRetries(t *testing.T) {
+	setRetries(t, 3)
+	var bodies []string
+	tr := newBGGTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		switch len(bodies) {
+		case 1:
+			return nil, errors.New("connection reset")
+		case 2:
+			return answer(http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}}), nil
+		}
+		return answer(http.StatusOK, nil), nil
+	}), 0, 1)
+
+	start := time.Now()
+	req, _ := http.NewRequest("POST", "http://bgg.test/slot", strings.NewReader("badge=1"))
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("answered %d", resp.StatusCode)
+	}
+	if len(bodies) != 3 || bodies[1] != "badge=1" || bodies[2] != "badge=1" {
+		t.Errorf("sent %q, want the body three times", bodies)
+	}
+	// The first retry waits at least half a second, the second as long as Retry-After asks
+	if waited := time.Since(start); waited < 1500*time.Millisecond {
+		t.Errorf("retried after %s", waited)
+	}
+	stats := tr.snapshot()
+	if stats.Requests != 3 || stats.Retries != 2 || stats.NetworkErrors != 1 || stats.TooManyRequests != 1 || stats.GaveUp != 0 {
+		t.Errorf("stats %+v", stats)
+	}
+}
+
+func TestTransportGivesUp(t *testing.T) {
+	setRetries(t, 0)
+	calls := 0
+	tr := newBGGTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return answer(http.StatusBadGateway, nil), nil
+	}), 0, 1)
+
+	req, _ := http.NewRequest("GET", "http://bgg.test/", nil)
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || calls != 1 {
+		t.Errorf("answered %d after %d attempts, want the 502 after one", resp.StatusCode, calls)
+	}
+	if stats := tr.snapshot(); stats.GaveUp != 1 || stats.ServerErrors != 1 || stats.Retries != 0 {
+		t.Errorf("stats %+v", stats)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	setRetries(t, 0)
+	var mu sync.Mutex
+	calls := 0
+	fail := true
+	sent := make(chan bool)
+	release := make(chan bool)
+	blocking := false
+	tr := newBGGTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		calls++
+		failing, block := fail, blocking
+		mu.Unlock()
+		if block {
+			sent <- true
+			<-release
+		}
+		if failing {
+			return nil, errors.New("connection refused")
+		}
+		return answer(http.StatusOK, nil), nil
+	}), 0, 1)
+	get := func() error {
+		req, _ := http.NewRequest("GET", "http://bgg.test/", nil)
+		resp, err := tr.RoundTrip(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	cooledDown := func() {
+		tr.mu.Lock()
+		tr.openUntil = time.Now().Add(-time.Second)
+		tr.mu.Unlock()
+	}
+
+	// Closed to open
+	for i := 1; i <= breakerThreshold; i++ {
+		if err := get(); err == nil {
+			t.Fatal("a failing request succeeded")
+		}
+		want := breakerClosed
+		if i == breakerThreshold {
+			want = breakerOpen
+		}
+		if breaker := tr.snapshot().Breaker; breaker != want {
+			t.Fatalf("after %d failures the breaker is %s, want %s", i, breaker, want)
+		}
+	}
+	if err := get(); err == nil {
+		t.Fatal("an open breaker let a request through")
+	} else if _, ok := err.(circuitOpenError); !ok {
+		t.Errorf("an open breaker returned %v", err)
+	}
+	if calls != breakerThreshold {
+		t.Errorf("an open breaker sent a request")
+	}
+
+	// Half-open: one trial, the others are turned away
+	cooledDown()
+	mu.Lock()
+	fail, blocking = false, true
+	mu.Unlock()
+	trial := make(chan error)
+	go func() { trial <- get() }()
+	<-sent
+	if breaker := tr.snapshot().Breaker; breaker != breakerHalfOpen {
+		t.Errorf("during the trial the breaker is %s", breaker)
+	}
+	if _, ok := get().(circuitOpenError); !ok {
+		t.Error("a request during the trial was not turned away")
+	}
+	mu.Lock()
+	blocking = false
+	mu.Unlock()
+	release <- true
+	if err := <-trial; err != nil {
+		t.Fatal(err)
+	}
+	if stats := tr.snapshot(); stats.Breaker != breakerClosed || stats.OpenUntil != nil {
+		t.Errorf("after a successful trial the breaker is %s", stats.Breaker)
+	}
+	if err := get(); err != nil {
+		t.Errorf("a closed breaker returned %v", err)
+	}
+
+	// A failed trial opens the breaker again
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	for i := 0; i < breakerThreshold; i++ {
+		get()
+	}
+	cooledDown()
+	if _, ok := get().(circuitOpenError); ok {
+		t.Fatal("no trial after the cooldown")
+	}
+	stats := tr.snapshot()
+	if stats.Breaker != breakerOpen || stats.OpenUntil == nil || !stats.OpenUntil.After(time.Now()) {
+		t.Errorf("after a failed trial the breaker is %s until %v", stats.Breaker, stats.OpenUntil)
+	}
+}
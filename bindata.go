@@ -89,7 +89,7 @@ func logosMicrobadger_headertPng() (*asset, error) {
 	return a, nil
 }
 
-var _webpageHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xec\x5c\xe9\x92\xdb\xb6\x96\xfe\xad\x7e\x0a\x5c\xda\x33\x92\x6c\x89\x6c\x6d\x49\x4a\x2d\x29\xe3\xa5\x52\xe3\x94\x6f\x26\xd7\x6d\xcf\xfd\x91\x49\xb9\x20\xf2\x48\x42\x9a\x24\x18\x00\xd4\x12\x95\xee\xfb\xcc\x6b\xcc\x93\x4d\x61\xe3\x22\x91\xad\xee\x76\x77\xca\x3f\xd2\xf7\x56\x44\x61\x39\xe7\xe0\x6c\x1f\x40\x1d\x78\xb2\x12\x51\x38\xbb\x40\x08\xa1\xc9\x0a\x70\x30\xbb\x68\x4c\x04\x11\x21\xcc\xfe\x4e\x7c\x46\x5f\xe3\x60\x09\x6c\xe2\xe9\xa6\x8b\xc6\x84\x8b\x9d\x7a\x40\xcf\x42\xba\x24\x71\x17\x33\xc0\x68\x7f\xd1\x90\x04\xd0\x86\x04\x62\x35\x46\xa3\xcb\xcb\x64\x7b\x65\xda\x16\x21\xc5\x62\x8c\x42\x58\x08\xd9\x74\x90\x53\x63\x2a\xc8\x82\xf8\x58\x10\x7a\x44\x21\xc2\x4c\x52\x95\xa3\x0b\x74\xe4\x24\x81\xe7\x21\x1c\x73\xea\x5d\x5e\xfe\x9b\x65\xa4\x06\x74\x43\xbc\xa3\xa9\x18\xa3\x05\xd9\x42\x90\xcd\x0d\x3a\x62\x55\x37\x57\x0d\x60\x59\x2f\x5d\x03\x5b\x84\x74\xd3\xdd\x8e\xb9\xcf\x68\x18\x5e\x5d\x58\xb1\x13\x06\x1c\x44\x37\x24\x5c\xe4\xf2\x6e\xbb\x2b\x20\xcb\x95\x18\x0f\x8a\xab\x36\x3c\xfa\xb9\x78\x19\xdd\x5d\x46\x57\x77\xcc\x29\x0b\x80\x8d\x51\x2f\xd9\x22\x4e\x43\x12\xa0\x25\xc3\x3b\x2b\x99\x8b\x7d\x12\xfc\xc6\xbb\x3e\xe7\x83\xae\x60\x00\x6b\x02\x9b\x13\x59\x8f\x69\x6e\x88\x80\x2e\x4f\xb0\x0f\x63\x14\xd3\x0d\xc3\x89\xed\xa9\x12\xb6\x5e\x82\xbc\xb7\xeb\xd3\x30\xc4\x09\x87\x31\xb2\x4f\x5a\xc4\x0b\xa9\x1e\xef\x85\x1c\xfc\x02\xbd\x8b\xf0\x12\x42\xe0\x1c\xbd\xb9\xbe\x1e\xa0\x8f\x46\x5e\x29\xcf\x0a\xbd\x59\x81\x7f\x33\xa7\x5b\x74\x9d\x26\x09\x65\x42\x4f\xf9\x8f\x18\x47\xa0\x44\x45\x1b\x12\x07\x74\xe3\xbe\xf2\x49\xf0\x23\x37\xbd\x7e\x88\x0d\x35\x4b\xcc\x74\xac\x81\x71\x42\x63\x34\x70\x2f\x4d\x0b\x4e\xc5\x8a\x32\xf4\x77\xcc\x04\x89\xd1\xbb\x35\x8e\xe9\xda\x74\xa5\x2c\x44\x01\xac\x21\xa4\x09\x30\xb4\x81\x39\x27\x02\xc6\x68\x25\x44\x32\xf6\xbc\x0d\x44\xf8\x06\x64\x13\x77\x63\x10\x5e\xe5\x24\xb1\x21\x42\x48\x2d\xc9\x49\x7c\xec\x79\xa6\xc1\xf5\x69\xe4\x3d\xfb\x5b\x91\x48\x0c\xa2\x92\xc4\x3c\xa4\x4b\xcb\x53\x9a\x35\x52\x92\xba\x1b\xca\x02\xe9\x5a\x5c\x91\x52\x33\x5f\xc8\x8f\x82\x5e\xdf\x52\xb4\xa3\x29\x0a\xc9\x0d\x20\xb1\x22\x5c\x9a\x29\x95\xd1\xf3\x3d\xfa\x39\x04\xcc\xa1\x83\x02\x1a\x63\x01\x63\x3d\xde\xca\xb8\xd9\x6c\xdc\x04\xef\x12\x1c\x2a\xda\xfe\x92\x74\xe7\x24\xf6\xa4\x02\x7c\xf6\xbd\x1f\x05\xd3\xcf\xbc\xbb\xf5\x43\xe2\xdf\xfc\xfb\x8a\x72\x01\xc1\xe7\x79\x2a\x04\x8d\x3f\x93\x60\xfa\x8f\x1f\x3e\xfd\xe7\xcf\xff\xfc\xf1\x75\xff\xc7\xb7\xaf\xaf\x4b\x62\x55\x3a\x65\xa7\xae\x03\xc9\x45\x58\x97\x4d\x70\x10\x90\x78\x39\x46\x97\x57\xa5\x90\x2f\x34\xc8\xf8\xea\xaa\x3c\x23\x9d\x37\x06\x1b\x0a\xb5\xf4\x43\x3c\x87\xf0\x97\x05\x65\xbf\x8e\xc7\x73\x58\x50\x06\xf5\xb2\xa8\xb1\x88\x27\x38\xb6\x63\x0b\xc2\xf9\x34\x16\x10\x8b\x31\x72\xfe\xa7\x3f\x9a\x7f\xe3\x58\x89\x02\xc2\x93\x10\xef\xc6\x88\xc4\x21\x89\xa1\x3b\x0f\xa9\x7f\x73\x2c\x7f\x3f\xd9\xa2\xcb\x7c\x15\x36\xcb\x0c\xf2\x38\x33\xb1\x57\x6a\x5b\x03\x13\xc4\xc7\x61\x17\x87\x64\x19\x8f\x91\xa0\x59\xa8\x0a\xd8\x0a\xdb\xec\x43\x2c\x80\x5d\x65\x72\x86\x94\x8d\xd1\x33\xf8\x76\xe8\x0f\xfc\x2c\xd3\xd2\x58\x74\x39\xf9\x03\xc6\xe8\xbb\x9c\x81\x12\xf8\x98\xf3\xed\xea\x24\x28\x0d\x0b\x5a\xc9\x0c\xa4\xfe\xd7\xef\xdf\x81\x44\xd1\xe2\xc7\x2b\x8c\x48\x10\x84\x67\x8d\x5a\x20\x20\xd7\x25\x3d\x81\x45\x38\x44\xbd\x5e\xb2\xf5\x7a\xdf\x24\x5b\xe4\x5c\xc3\x92\x02\xfa\xf4\xce\xe9\xa0\x57\x8c\xe0\xb0\x83\xae\x71\xcc\xbb\x1c\x18\x59\xdc\x61\x91\x05\x0e\xdd\x0d\xcc\x6f\x88\xe8\xa6\x1c\x58\x97\x43\x08\xbe\xc8\x5d\x4f\x0d\x88\xe8\x1f\xf5\xbd\x95\x1d\xb7\x72\x27\x71\x92\x8a\x5f\xc4\x2e\x81\xa9\xe3\x9b\xb4\xe8\xfc\x5a\x90\x28\xf3\xb8\xbb\x07\x40\xd1\x8f\x53\xc6\xa5\x83\x24\x94\x58\xb7\xb9\x67\x00\x55\x28\x47\x30\x1c\xf3\x05\x65\xd1\x18\xa9\xc7\x10\x0b\xd8\xb6\xba\xfd\x61\xb2\x6d\x97\xf4\x74\xb7\x81\xfc\x6e\xe3\xe8\x9d\x86\x9d\x1b\x73\x7e\xf5\x75\x29\xe1\xf6\xd5\x4b\x47\xbc\xcb\xe2\x8f\xc6\xd5\xad\xbd\x3c\xac\x6e\xe9\xa5\x51\x67\x86\x3c\xc0\x0b\x7f\x21\xc1\xaf\x63\xf5\x15\x02\xf4\xaf\xdb\x7d\xa3\x9c\x30\x7d\xe7\x4b\x58\xc6\x54\xb4\x2c\xdf\x36\xfa\x57\x39\x07\x3d\x20\x1e\x14\x41\x25\x78\xbb\x32\x99\x7d\x97\xe7\xeb\x87\xbb\x47\xae\x00\xe7\x78\x37\xa5\x77\x52\x72\x4f\xf5\xac\x37\xf8\x76\x34\x1f\x1c\x67\xef\x72\x2b\x4d\xb0\x4f\xc4\x6e\x8c\xdc\xd1\x5d\x65\x52\xca\xcc\x4c\xf5\xf2\x2e\xa8\xf6\x6d\x6f\x58\x10\x74\xdb\xe5\x2b\x1c\xd0\x8d\xce\xed\x12\xc0\xd8\x72\x8e\x5b\x97\x1d\xa4\xff\xef\xf6\x47\x6d\x44\x62\x0e\xe2\x44\xca\x9e\xd9\xfd\x29\x21\x2f\x1a\x13\xcf\x1e\x0c\x26\xdc\x67\x24\x11\x88\x33\x7f\xea\xd8\x7d\x08\xfe\x0d\x6f\xdd\x25\xa5\xcb\x10\x70\x42\xf4\x46\x47\xb6\x79\x21\x99\x73\xef\xb7\xdf\x53\x60\x3b\x6f\xe0\xf6\xdc\x9e\xf9\xe2\x46\x24\x76\x7f\xe3\xce\x6c\xe2\x69\x7a\x39\x65\x79\xf8\x58\xa4\xb1\x2f\xb7\x3f\x88\xa7\xf3\x1f\x28\x8b\x50\x2b\xa1\x5c\x7c\x62\x61\x07\xc9\x58\x78\xf7\xb6\x83\x22\xe0\x1c\x2f\xa1\x6d\xb5\xf0\xdc\x95\x0c\x5b\xfb\x8b\x46\x03\xa5\x2c\x1c\x3b\x0e\x7a\x89\xec\x2c\xd9\x28\x9d\x72\xdc\x94\x2d\x4d\xf5\x3d\xc0\x02\x7f\x54\x6d\xf2\x88\x94\xb7\x8d\x9f\xb7\x9c\x67\x72\xb2\xe6\xd4\x76\x25\xe0\xe0\x90\xfc\x01\xad\xb6\x1a\xc4\x53\xdf\x07\xce\xc7\x56\xc8\x56\x5b\x31\xd5\x42\x2c\x41\xb4\x2e\x1a\x8d\x06\x72\x3c\x75\x0a\xda\x39\x1d\xf5\x75\x5f\x3c\x13\x21\xe9\x4f\x2f\xcd\x0a\x0e\x1d\x3b\x5b\x46\x74\x03\xe9\xef\xc0\x18\x65\x39\x8b\xed\x8a\x75\x10\x17\x58\xa4\xbc\xa3\xfb\x72\xa6\x38\x04\x26\x5a\x8e\x6a\x45\x41\xca\x48\xbc\x54\xb2\x4b\xe5\x45\x84\xcb\x5d\xf4\x18\xc9\x05\x6d\x57\xcc\x65\xc0\x13\x1a\x73\xf8\x08\x5b\x61\xf8\x19\x05\x1e\xb2\x84\x92\x69\x1f\x07\xc1\x1b\xed\x5c\xad\x05\x8b\xda\x28\xd7\xb5\x54\xa3\x5c\x27\x72\x3c\x1e\x52\x71\x2d\x39\x09\xb5\x54\xf4\xbc\xd5\x7c\xd6\x94\xea\x63\xd1\xa9\xee\x32\xd2\x2d\xa9\x6a\x49\x11\x55\xfd\x31\xe0\x69\x28\xd0\x54\x19\xc4\x48\x59\x1a\xd0\x3e\x9a\xe7\x1a\xa3\xb4\x72\xa3\x20\xad\x20\xa3\x9d\x15\x84\x21\x75\xda\x57\x47\xf3\x0e\x27\x84\x7c\x1a\x25\x21\x08\x28\x51\x42\x17\x67\xe7\x29\xf5\xd7\xb1\x6f\xbe\x8a\xb5\xd5\xd0\x0a\x73\x44\x7d\x3f\x65\x0c\x02\xb7\x59\x21\xcf\x95\x7e\xb8\x30\xaa\x66\x20\x52\x16\xa3\x05\x0e\x39\x5c\x79\x9e\x39\x1d\x08\x9a\x70\x24\x56\xa0\xed\xbc\x60\x34\x42\xd8\x17\x29\x0e\xc3\x9d\xf2\x79\x12\x2f\x4f\x6c\x99\x0a\xfa\x01\x16\x0c\xf8\xaa\x45\x82\xf6\xde\x32\xe0\x20\x3e\x92\x08\x68\x2a\x5a\x47\x0e\x6d\x0d\x49\x82\xb6\x1b\x52\x1c\xb4\x02\xea\xa7\x11\xc4\xc2\xfd\xf4\xe1\x3d\x7a\x89\x50\x13\xd9\x7e\x65\xa2\x23\x0e\x36\xa5\x1c\x3a\xf2\x28\x7e\x79\xd9\xce\x32\x4a\x26\x93\x4a\x6d\xd7\xe9\xfc\x35\xdd\x02\x6f\xcd\xe9\x56\x06\xb6\x3a\x11\xbe\x7b\x9b\x07\x76\xcb\x71\xa5\xf7\xda\x76\x37\x61\x34\x69\x39\x26\x2d\x3a\x1d\x1b\xae\x6a\x7a\xdb\x25\xbc\xe5\xd8\x9c\xe9\xb4\x33\x29\x4e\xa8\xf8\x2b\x1c\x2f\xa1\xd5\x2e\xe6\x39\xef\x85\x1a\x57\x95\x92\x9d\xb6\x1b\x40\x08\x4b\x2c\xa0\xe5\x9c\xa4\x67\x89\x72\x1d\xe4\x68\x9a\x4e\x07\x95\xdd\x40\xf2\x5f\x63\xa6\x1f\xec\x78\x34\x45\xcf\x5b\xd2\x9a\x32\x30\x64\x47\x0c\xf2\x7c\xf6\x9e\x70\xe9\xf7\x76\x94\x9b\x60\x26\xc3\xaf\xed\xc6\xb0\xcd\x3f\xcc\x14\xbd\x27\xfd\x29\x9b\xf8\x26\xa7\x9d\x53\x73\x17\x24\x0e\x8c\xcc\x05\xcc\x3c\x16\xdf\x6a\x4a\xff\x97\x2c\x5a\x99\x08\x47\x1a\xb5\x2b\x32\x9e\x59\x27\xc3\xb1\x99\x90\x60\x29\x58\x26\x87\xdb\xe5\x3f\x99\xab\xdc\x3f\x9b\xdc\xbe\x7a\xe1\x5d\x28\x4c\x32\x80\x21\x5b\x27\x9e\x7e\xbd\xa5\x9e\xe7\x34\xd8\xcd\xb2\xd0\x9a\x90\x68\xa9\xf1\x4a\x8d\x01\xe6\x20\x85\x66\x53\x47\x1f\xe2\x86\xbd\x51\xb2\xbd\xb2\xc7\xb7\xde\x77\x23\x07\x79\xb3\x8b\xc6\x7e\x4f\x16\xda\x10\x9f\x92\x00\x0b\x40\x87\xc3\x45\x63\x12\x90\x35\x22\xc1\xd4\x49\x55\x9b\x33\xd3\x32\x4d\x56\xc3\xd9\x4f\xb0\x41\x51\xfe\x52\x0d\xd9\x37\x18\x78\x8d\x49\xa8\xde\x6e\x4d\x30\x5a\x31\x58\xe4\xb8\xb9\x24\x62\x95\xce\x35\x5c\x86\xa1\x3c\x03\xfa\xab\x98\x86\x74\xb9\xf3\x0a\x94\x3c\x06\xea\x25\x00\xf7\x02\xba\x89\x65\x28\x7a\xfb\xfd\x12\xc4\x7b\x2c\x80\x8b\xff\xd6\x6c\x0e\x07\x3d\x65\xae\xa6\x7c\x56\x03\xfe\x8b\x1f\x0e\xfa\xe9\x15\xf3\x57\x87\x83\x33\x7b\x6b\x08\xa0\x9f\xe8\x06\x4d\x3c\x3c\x9b\x78\xab\xa1\xc4\x5e\x2f\x20\x6b\xb5\x66\x88\x83\xd2\x3a\x23\x88\xd3\x6c\x95\x32\xdd\xcc\x2e\x1a\x8d\x89\x7e\x8b\x80\xf4\x46\x8f\xeb\xec\xaf\x86\xff\x9e\x12\xd1\xd5\xbd\x0e\x52\x6f\x15\xa7\xce\x3f\x52\x22\x4a\x9a\xc1\x71\xa0\x72\x18\x62\x38\x0e\x68\x44\xfe\x90\x90\x95\x4b\xcf\x1d\x95\xd7\xb0\x0a\xa1\xa9\xe3\x49\x9a\xce\x4c\x52\x99\x78\x9a\xb4\x95\xc7\x33\x02\x59\xf1\x27\x73\xa6\x4c\x97\x49\x9f\xbf\xc4\x2c\xad\x01\x65\xb4\xd5\x00\x07\x45\x20\x56\x34\x98\x3a\x32\x79\x3a\x85\x99\x72\xb0\x9c\xd9\xf8\xc4\x81\xc5\x38\x82\x31\x92\xcb\x57\xb1\x63\x56\x2f\x8f\xf1\x0e\x92\x7d\x53\x27\x35\xa3\x1c\x95\x0c\x17\xd4\x4f\xb9\xa7\xf5\xa5\xe5\x6a\xfc\x8c\x39\xdf\x50\x16\x9c\x92\x49\x4c\x8f\x25\x95\x7f\x97\xd2\xd8\x6f\xdd\x05\x81\x30\x70\xca\x44\x27\x7f\xeb\x76\x51\xd9\x24\xd6\x02\x34\x7e\x13\x12\xff\xc6\x2c\xa7\xd5\x2e\xae\xed\xc8\x4a\xd7\x78\x0d\x0a\x53\x54\x2f\x22\xb1\xb2\x81\xca\xd3\x21\xf5\x15\xb4\x2c\x28\x43\x8b\x54\xa4\x0c\xe4\x99\xd8\x99\xa9\x29\xef\xe5\xf0\xcc\x30\xa8\xdb\x3d\x75\x90\x07\x48\xf3\x9e\x2e\x11\x89\x05\x45\x73\x8a\x59\xb0\xc4\x11\x2c\x01\x6e\x64\xa4\x18\xef\xc1\x4c\xd4\xba\xcf\xac\x2c\x93\x94\x27\xdb\x5b\x4a\x5c\xb3\x40\xd6\x76\x19\xe0\x60\x77\x0c\x7c\x1a\x2f\x9a\xcf\xca\x4a\x6f\xb6\xdd\x1b\xd8\xa9\x17\x79\xf9\x04\xb5\xff\x6c\x34\x64\xbe\x04\xd9\xfd\x86\x06\x30\x9d\xf6\x06\x6d\xd5\x6a\x09\x15\x57\xd8\x6c\xbb\xea\x7d\x5c\x4b\x83\x26\xb2\x3b\xaf\x86\xfa\x28\xed\x91\x8c\x96\x0a\xbb\xcb\x6c\x8b\xab\xf7\xb8\x4d\xed\xbe\x4d\xbd\xc5\x3c\xda\xe0\x66\xbb\x59\xcb\x5f\xda\xb3\x59\xda\x92\x1d\x09\x20\xff\xbc\x17\x25\x1c\x6f\xca\xdd\x5d\xb7\xd7\x54\x03\x1a\xa7\x3d\xfd\xda\x9e\x41\x6d\xcf\xb0\xb6\x67\xd4\x54\xa9\xbd\xa1\x11\xb9\x90\xe0\xad\x8f\x17\x03\xc6\x66\x9d\x35\x0e\x53\xeb\xbe\xef\x75\x34\x7b\xc6\x0d\x4b\x39\x42\x23\xc1\x82\xe1\x08\x0c\x18\x48\x9e\x5e\x4f\x3b\xa1\x5e\x67\xd7\x9c\x43\x8f\x00\x42\x1e\xb7\xaf\x2c\x3c\x7c\x93\x6c\x1d\xa4\xc8\xbc\x56\x27\xc1\xa9\x73\x29\x8f\x31\x9a\x72\x3d\x9f\x7e\x81\x4f\xff\x09\xf9\x0c\x0a\x7c\x06\x4f\xc8\x67\x58\xe0\x33\x7c\x42\x3e\xa3\x02\x9f\xd1\x23\xf0\x29\x80\x85\x05\x89\x93\x9f\xab\x1c\x54\x29\x4f\x71\x5c\x49\x74\x43\xb1\x40\x52\x8a\xcb\x33\xc8\x51\x3f\x5d\x29\x2f\x2e\x83\x4f\xe1\xe8\x54\x81\x40\x6a\xc9\xda\xcb\x35\x0e\x21\x1a\xeb\xd1\x53\xa7\x70\x32\x6b\x1e\x8f\x6b\xb6\x15\x62\x69\xce\x4c\x3e\x36\x26\x62\x35\xbb\x0e\xa9\x40\xbd\x89\x27\x56\xe5\xa6\xfe\x69\xd3\xe0\xb4\x69\x78\xda\x34\xb2\x4d\x3a\xcc\x34\xab\x32\xd7\x60\x66\xf3\xa0\xd2\x8c\xda\x7c\x4f\x9d\xca\xfd\x75\x21\x0a\x95\xf8\x8d\xc6\x24\x0d\xf5\x83\x9a\x1f\x12\xa3\x4d\x84\x54\x67\x31\x15\x64\x9b\x58\x64\xb6\x8c\xd3\x6c\xef\x58\x0c\x6e\xbd\xdd\xec\xe2\x30\xec\x62\xc6\xe8\xc6\xf1\x66\x13\xb5\x2d\x9e\xd5\x50\xab\x9c\xab\x60\x4c\x6d\xf4\xcd\xd0\xec\x00\xd3\x3c\x19\xdb\xec\xd8\x36\x1f\x0b\x58\x52\xb6\x6b\xb6\x25\x57\x9e\xe0\x78\x36\xf1\xcc\x87\x91\x41\x9f\x2a\x16\x94\xd5\x0b\x3c\x9b\xcc\x67\xd7\xaa\x11\xbd\x0a\x43\xd4\xda\xef\x89\x80\xe8\x3a\x8d\x90\x7b\x38\xb4\x27\xde\x3c\xa3\x86\x94\xe6\x1a\xfb\x3d\x93\x92\xa2\xe7\x37\xb0\xeb\x3c\x57\xa9\x12\x8d\xa7\x72\xb4\x19\xa0\x95\x9c\xeb\x55\x7b\xfd\x39\x6d\xec\xf7\xee\x47\x46\xa2\x7f\xae\x88\x80\x6b\xf5\xdb\x9e\x64\x70\x38\x18\x31\x2b\xcc\x70\x0f\x55\xd7\x11\x77\xac\xff\x1c\xa9\xf4\xbc\x41\xea\x28\xe6\xe6\xa9\x5d\x50\x34\xbf\x97\xc5\xce\x28\x46\xda\x6f\xbf\xd7\x4d\xd2\x7a\x21\xc4\x48\x5b\xe5\xc8\x7c\xda\x38\xca\x18\x36\x0a\x0a\xc6\x8c\xe6\xd2\x88\x76\xa2\xe9\x3d\x8e\x90\x3b\x9b\xf2\x79\x34\x77\xdf\x05\xb9\xf1\x1e\x60\x3d\xbd\x65\x95\x14\x7b\x19\x20\xe7\x84\x6b\xf8\x1d\xdb\x73\xbf\xd7\x2b\xaa\xb5\x84\x83\x32\x0d\x90\x38\x80\x6d\xe7\xb9\x4a\xa0\x2a\x1e\x20\x50\x2a\x89\xe6\xae\xfd\x7e\x38\x20\xa4\x8e\x70\xf0\xbb\x19\x8f\x2e\x0f\x07\xdd\x54\x9a\x78\x38\x98\x75\x9a\xc3\x0f\xb2\x9f\xf6\xe1\x3e\xe6\x3f\x52\xe6\x2c\x3f\x7b\x6a\xc6\xb2\x3b\x5a\x7e\xfa\xf0\x5e\xf1\x39\xfa\x0a\x21\x87\xc3\x21\xfb\x75\x77\xb7\xe2\x2e\x4e\xf8\x1a\xbb\x29\xf7\x36\x49\xd7\xbc\xe8\xf5\xd2\x44\x1e\xe1\xb8\x17\xc2\x12\xfb\xbb\xcf\x98\x73\x10\x5c\x8e\xf6\x2e\x07\xfd\x79\x00\x03\x7f\x14\x74\xf5\xcb\xc2\xcf\x4b\x86\x77\x6e\x12\x2f\xed\x5a\xd4\xe6\x48\x33\x7e\x0b\x7a\x7b\xa5\x0e\x8f\x65\xc7\x6b\x4c\x3c\xe5\x4d\xc6\xed\xcc\x99\x30\x73\x2a\x2f\xf3\xc9\xc2\x63\x71\x58\xd6\xac\x87\x6b\xbc\x95\xcd\x06\x11\x1e\x88\x0c\xfd\xa7\x41\x86\xfe\x17\x20\x43\xff\x1e\xc8\xd0\xaf\x40\x86\xfe\x43\x90\xa1\x42\xe0\xaf\x03\x19\xfa\x4f\x89\x0c\xf5\xc4\xcb\x99\xa4\x7f\x67\x64\xa8\xa7\x98\x9b\xe7\x71\x90\xe1\xac\x62\xbe\x3a\x64\xe8\x3f\x36\x32\xf4\x6f\x47\x86\x7e\x2d\x32\xf4\xff\x04\x64\xe8\x3d\x2d\x32\x54\x28\xf3\x2f\x64\xc8\x90\xe1\x31\xa0\x61\xf0\x34\xd0\x30\xf8\x02\x68\x18\xdc\x03\x1a\x06\x15\xd0\x30\x78\x08\x34\x54\x08\xfc\x75\x40\xc3\xe0\x29\xa1\xa1\x9e\x78\x39\x95\x0c\xee\x0c\x0d\xf5\x14\x73\xf3\x3c\x0e\x34\x9c\x55\xcc\x57\x07\x0d\x83\xc7\x86\x86\xc1\xed\xd0\x30\xa8\x85\x86\xc1\x9f\x00\x0d\xfd\xa7\x85\x86\x0a\x65\xfe\x05\x0d\x8f\x0a\x0d\xc3\xa7\x81\x86\xe1\x17\x40\xc3\xf0\x1e\xd0\x30\xac\x80\x86\xe1\x43\xa0\xa1\x42\xe0\xaf\x03\x1a\x86\x4f\x09\x0d\xf5\xc4\xcb\xa9\x64\x78\x67\x68\xa8\xa7\x98\x9b\xe7\x71\xa0\xe1\xac\x62\xbe\x3a\x68\x18\x3e\x36\x34\x0c\x6f\x87\x86\x61\x2d\x34\x0c\xff\x04\x68\x18\x3c\x2d\x34\x54\x28\xf3\x2f\x68\x78\x54\x68\x18\x3d\x0d\x34\x8c\xbe\x00\x1a\x46\xf7\x80\x86\x51\x05\x34\x8c\x1e\x02\x0d\x15\x02\x7f\x1d\xd0\x30\x7a\x4a\x68\xa8\x27\x5e\x4e\x25\xa3\x3b\x43\x43\x3d\xc5\xdc\x3c\x8f\x03\x0d\x67\x15\xf3\xd5\x41\xc3\xe8\xb1\xa1\x61\x74\x3b\x34\x8c\x6a\xa1\x61\xf4\x27\x40\xc3\xf0\x69\xa1\xa1\x42\x99\x7f\x41\xc3\xe9\x4f\x0d\x45\x8c\xa8\xf8\x95\xda\x56\x11\xd8\x92\x7e\x75\xf9\xc0\xb1\x80\x72\x4b\xaf\x2e\x32\xbc\xb5\xc4\x89\xa7\xf3\xeb\x90\x8a\x1f\x28\x8b\x6c\xa1\x53\xf1\x87\x7a\x33\x7e\xa6\x7f\xd0\x47\xea\x07\xf5\x37\x2b\x4a\x7c\xe0\xc5\xba\x25\xcd\xe9\x6c\xed\xcb\x29\x91\xbc\x08\x26\xd7\x40\x56\x0a\xd3\xb8\x38\xb3\xc0\x6c\x86\x08\x66\x95\x15\xda\xba\x90\x41\x2d\x0a\xaf\xa1\xab\x6f\xce\x3a\x85\xca\x06\xbc\x86\x9f\x75\xa3\x16\xa1\x28\x3d\x04\x24\xab\x98\x33\x77\x6e\x55\xd1\x9c\xa7\x87\x9e\xd5\xab\xd2\x69\xb3\xc0\xa3\xd9\x41\xcd\x82\x1c\xcd\x4e\x53\xb7\x23\xd9\x18\x34\xdb\xa6\x52\x0d\x73\xa4\xdb\xf3\x6a\xb5\xea\xc5\x65\x7a\xaa\xf5\x9d\x42\x39\x99\xfc\x2b\xde\x57\xb0\x56\x47\x2d\x53\x1b\x56\x2a\xdb\x92\x7f\xea\x7a\xc2\x71\xdd\xbc\xb9\xee\x73\x5c\xc3\x25\xff\xb2\x5b\x09\x27\x15\x21\x27\x05\xf6\xa6\xa6\xbb\xf2\x86\xc2\x91\x28\x45\x59\xf4\x55\x85\xef\x8b\x05\x2e\x53\xb9\x8e\x97\xbe\xf6\xa6\x97\x9a\xa9\x80\x40\xcb\x5a\x14\x76\x09\xa2\xa9\xdb\x74\x19\x99\x29\x29\xbb\xc8\x5b\x4c\x43\xa6\x47\xab\x3c\x5b\xa0\x65\xcb\x63\x6c\xf4\x96\xea\x37\x71\x60\xac\xca\x6b\x2b\x38\x71\x50\x70\xb5\xc9\x6a\x30\x33\xd6\xd7\xf1\x40\xe3\x05\x59\xa6\x4c\x2d\x8a\x4f\xbc\xd5\x40\x8d\xb2\x0c\x0a\x77\xbe\x55\xd5\x8f\xe2\x90\xe5\xfe\xb5\xcc\xf4\x4b\x10\x9a\x20\x57\x29\x47\x6f\x11\xcf\x43\x94\x8d\x89\x1c\xa3\xd6\x87\x83\x33\xd3\x9f\x59\x62\xd7\x35\x9c\x19\x5f\x9b\xd7\xb2\x44\x96\x17\x79\x9a\x86\x13\xa8\x3d\xca\x05\xef\x29\x0e\x50\x06\x54\x46\x70\xc7\xd0\x30\x54\x51\x45\xed\x6c\xb9\x78\xb6\x5a\xd5\x1c\xc4\xbb\x58\x00\x5b\xe3\xd0\x41\x15\x15\x4b\xc4\x74\xe6\x65\xb3\x1f\x4c\x85\xa6\xae\x23\xb5\xfd\xa8\x15\x91\x38\x15\xc0\xdb\xa7\x75\xb0\x71\x1a\xcd\x81\x59\x25\x92\x8c\x5d\x44\xe2\x69\xcf\x2e\xb2\xe7\xcc\x72\xc5\x9c\xcd\x17\x56\x66\x93\x8b\x4d\xce\xad\xab\x25\x2e\xc7\xf7\x49\x78\x17\x89\x21\x7b\x53\xa2\x70\xf7\xa8\x10\xe0\x05\x6d\x65\x77\x7c\x8e\x6f\x21\x95\xe2\xbb\xac\xbf\xd3\xdb\x33\xb7\xc4\x76\x5d\x96\xa9\x8a\x6c\x2b\xd5\x4b\xe9\x9f\xc7\xb9\xc7\x86\x73\x39\x76\xed\xf7\x2c\x94\x8f\x23\xb9\x58\x54\x57\xed\x3c\xb6\x58\x17\xaa\x6a\xdd\x74\x67\xee\x37\xe7\x8d\xaa\x3d\xcb\xc2\xeb\xcc\x3a\x1a\xa0\x98\x6e\x1e\x68\xda\x9c\xe4\xed\x86\xcd\x57\x72\x37\xb3\x16\x17\xf7\x64\x46\xfd\x90\xd7\x42\xbf\x2c\xd4\x42\xbf\x8c\xe9\xe6\x91\x6d\x6c\xae\x5b\xe4\x77\x2c\x4c\x28\xde\xfb\x71\x41\xa9\x00\x89\xa9\x15\xb7\x26\xc6\x48\xdd\x5b\xc8\x2e\x37\xd8\x70\xff\xbf\xff\x45\xfd\xcb\xde\xb7\xe8\x1a\x47\x29\x84\xf2\x68\x0a\x71\x47\x7f\xa0\x8f\xd9\xed\x09\x74\x6d\xfe\xfd\x04\x5e\x48\x6b\xc5\xcb\x17\x63\xef\xf8\xc2\x85\x6b\xff\xc9\x05\xee\xcc\xce\x8d\x98\x78\xd8\x5e\x3a\xb1\x6b\x98\x78\xfa\x1f\x5a\xb9\xf8\xff\x00\x00\x00\xff\xff\xfa\x1b\x54\x63\x71\x45\x00\x00")
+var _webpageHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xb4\x3b\xdb\x76\x1b\xb9\x91\xcf\xd4\x57\x54\x60\x6d\x48\x8e\xc9\x6e\x51\xb2\x3d\x13\x8a\xe4\xac\x2f\x9b\xc4\x39\xce\x64\x62\xd9\xc9\x43\x36\xc7\x07\xec\x2e\xb2\x31\xea\x6e\xf4\x00\x68\x51\x1c\xa6\xf3\x3f\xfb\x1b\xfb\x65\x7b\x70\xeb\x0b\x2f\x92\xe2\xdd\xd5\xcc\xb1\xd0\x40\xa1\x50\x37\x14\xaa\x0a\xd0\x2c\x51\x59\xba\x38\x03\x00\x98\x25\x48\xe3\xc5\x59\x6f\xa6\x98\x4a\x71\xf1\x47\x16\x09\xfe\x86\xc6\x6b\x14\xb3\xd0\x76\x9d\xf5\x66\x52\x6d\x4d\x03\x9e\xa5\x7c\xcd\xf2\x31\x15\x48\x61\x77\xd6\x03\xfd\xb3\x61\xb1\x4a\xa6\xf0\xf2\xe2\xa2\xb8\xbf\x76\x7d\xab\x94\x53\x35\x85\x14\x57\x4a\x77\x55\x7a\x2a\x8d\x22\x5e\xe6\xaa\x3b\x39\xa3\x42\x23\x5c\x72\xa5\x78\x36\x85\x89\xc3\x61\x26\xe4\x5c\xb1\x15\x8b\xa8\x62\x3c\x3f\x3a\x4b\xa3\x6f\x2d\xac\x27\x29\xba\x4c\x71\x9f\xb4\xc9\xc5\xc5\xbf\x79\xca\x0c\xc0\x38\xa5\x5b\x5e\xaa\x29\xac\xd8\x3d\xc6\xf5\xdc\x78\xa4\x92\x53\x73\x0d\x80\xa8\x47\xf9\x1d\x8a\x55\xca\x37\xe3\xfb\xa9\x8c\x04\x4f\xd3\xeb\x33\x07\x14\x2c\xb5\xf4\xc6\x8a\xa5\xe8\x81\x63\x26\x8b\x94\x6e\xa7\x90\xf3\x1c\xaf\x6b\x1e\xee\xc7\x09\xb2\x75\xa2\xa6\xf0\xaa\xc5\xc1\x92\xc7\xdb\x40\xcf\x1e\xdf\x31\xdc\x3c\x88\x8e\xe5\x29\xcb\xf1\xe1\x89\x32\xa3\x69\x7a\x92\x90\x16\xc1\x1b\x43\xcb\x1e\xf7\x2f\x5e\xb6\xf4\x51\x08\x94\xa8\xc6\x29\x93\x6a\x77\xc8\xc4\x55\x5b\xff\x6e\xfa\x65\x23\xf7\x5a\x60\xdb\x5a\x60\x76\x60\xc9\x45\x8c\x62\x0a\x93\xe2\x1e\x24\x4f\x59\x0c\x6b\x41\xb7\x35\x71\x34\x62\xf1\x4f\x72\x1c\x49\x79\x35\x56\x02\x51\xf3\xb6\x7b\x0c\xe7\x86\x29\x1c\xcb\x82\x46\xa8\x59\xdd\x08\x5a\xf8\x91\x63\xc4\x9e\xa6\xa0\x19\x1d\x47\x3c\x4d\x69\x21\x71\x0a\xbe\x65\x49\x3c\xd3\x7a\x0f\xbf\xd1\xc0\xdf\xc0\xfb\x8c\xae\x31\x45\x29\xe1\xed\xcd\xcd\x15\x7c\x72\xf4\x6a\x7a\x12\x78\x9b\x60\x74\xbb\xe4\xf7\x70\x53\x16\x05\x17\xca\x4e\xf9\xf7\x9c\x66\x68\x48\x85\x0d\xcb\x63\xbe\x09\x5e\x47\x2c\xfe\x83\x74\xa3\x51\x4a\x1d\x36\x8f\xcc\x0d\xdc\xa1\x90\x8c\xe7\x70\x15\x5c\xb8\x1e\x5a\xaa\x84\x0b\xf8\x23\x15\x8a\xe5\xf0\xfe\x8e\xe6\xfc\xce\x0d\x95\x22\x85\x18\xef\x30\xe5\x05\x0a\xd8\xe0\x52\x32\x85\x53\x48\x94\x2a\xa6\x61\xb8\xc1\x8c\xde\xa2\xee\x92\x41\x8e\x2a\x3c\x3a\x49\x6d\x98\x52\x28\xec\x24\x39\x0d\x43\xd7\x11\x44\x3c\x0b\x9f\xfd\xaa\x8d\x24\x47\x75\x14\xc5\x32\xe5\x6b\xbf\xa6\x56\x6b\x66\x28\x0d\x36\x5c\xc4\xda\xb4\xa4\x41\x65\x66\x7e\xa3\x7f\xb5\xe4\xfa\x8e\xc3\x96\x97\x90\xb2\x5b\x04\x95\x30\xa9\xd5\x54\x6a\xb7\xf0\x3d\xfc\x98\x22\x95\x38\x82\x98\xe7\x54\xe1\xd4\xc2\x7b\x1a\x37\x9b\x4d\x50\xd0\x6d\x41\x53\x83\x3b\x5a\xb3\xf1\x92\xe5\xa1\x16\x40\x24\xbe\x8f\xb2\x78\xfe\x45\x8e\xef\xa3\x94\x45\xb7\xbf\x4e\xb8\x54\x18\x7f\x59\x96\x4a\xf1\xfc\x0b\x8b\xe7\x7f\xfe\xed\xe7\xdf\xff\xf8\xd7\x3f\xbc\xb9\xfc\xc3\xbb\x37\x37\x1d\xb2\x8e\x1a\xe5\xe8\xd4\x00\x68\x26\xbc\xc9\x16\x34\x8e\x59\xbe\x9e\xc2\xc5\x75\xc7\x97\xb5\x3a\xf4\xfe\x1a\x1b\x8f\xdb\xdd\xa7\x27\xf1\xa7\x74\x89\xe9\xdf\x56\x5c\xfc\x7d\x3a\x5d\xe2\x8a\x0b\x1c\x3d\x0c\x0b\xb2\xa0\xb9\x87\x6d\x11\x17\xf1\x5c\x61\xae\xa6\x40\xfe\xf3\xf2\xe5\xf2\x15\xb9\x3e\xee\x70\xc6\xcb\x94\x47\xb7\xfb\xf4\x5f\x16\xf7\x70\x01\x17\x7b\x1e\x60\x72\x55\xdc\xef\xed\xbd\x4e\xdf\x1d\x0a\xc5\x22\x9a\x8e\x69\xca\xd6\xf9\x14\x14\xaf\xb7\xaa\xc2\x7b\xe5\xbb\x23\xcc\x15\x8a\xeb\x9a\xce\x94\x8b\x29\x3c\xc3\x6f\x5f\x44\x57\x51\x7d\xe6\xf0\x5c\x8d\x25\xfb\x05\xa7\xf0\x5d\xb3\x80\x21\x78\x7f\xe5\x87\xc5\xc9\xa0\x4c\x5b\x52\xa9\x15\x64\xfe\xbb\xbc\x7c\x02\x8a\xb6\xc6\xf7\x39\xcc\x58\x1c\xa7\x8f\x2a\xb5\x85\x40\xf3\xa5\x2d\x41\x64\x34\x85\xc9\xa4\xb8\x0f\x27\xaf\x8a\x7b\x20\x37\xb8\xe6\x08\x9f\xdf\x93\x11\xbc\x16\x8c\xa6\x23\xb8\xa1\xb9\x1c\x4b\x14\x6c\xf5\x04\x26\x5b\x2b\x8c\x37\xb8\xbc\x65\x6a\x5c\x4a\x14\x63\x89\x29\x46\xaa\x7b\x56\x8d\x33\xfe\xcb\xe9\xd1\xa3\x03\x0f\xae\xce\xf2\xa2\x54\x7f\x53\xdb\x02\xe7\x24\x72\x6e\x91\xfc\xbd\x45\xd1\xd1\x83\xea\x61\xa3\x6e\xdb\x71\x29\xa4\x36\x90\x82\x33\x6f\x36\xff\xe2\x06\x3a\x22\x1c\x25\x68\x2e\x57\x5c\x64\x53\x30\xcd\x94\x2a\xbc\x1f\x8c\x2f\x5f\x14\xf7\xc3\x8e\x9c\x9e\x06\x28\x9f\x06\xc7\x9f\x04\xf6\x18\xcc\xe3\xdc\x9f\x72\x09\x0f\x73\x3f\x79\xe5\x16\x78\x84\xf9\xc9\xab\x27\xf1\x3e\x79\xf5\x14\xd6\x3b\x50\x8f\x80\x7c\x85\x15\xfe\x8d\xc5\x7f\x9f\x9a\x4f\x8c\xe1\x9f\x0f\xdb\x46\xd7\x61\x46\xe4\x7f\xb3\x64\xce\xd5\xc0\xaf\x3b\x84\x7f\x76\x7d\xd0\x57\xec\x07\x83\xd0\x10\x3e\x3c\xea\xcc\xbe\x6b\xfc\xf5\xd7\x9b\x47\x23\x00\xb2\x1f\x4d\xd9\x48\x4a\xc7\x54\xcf\x26\x57\xdf\xbe\x5c\x5e\xed\x7b\xef\x6e\x2f\x2f\x68\xc4\xd4\x76\x0a\xc1\xcb\xa7\xd2\x64\x84\x59\xab\xea\xf9\x53\x4e\xb5\x6f\x27\x2f\x5a\x84\xde\x8f\x65\x42\x63\xbe\xb1\xbe\x5d\x1f\x60\x62\xbd\xa4\x83\x8b\x11\xd8\xff\x83\xcb\x97\x43\x60\xb9\x44\x75\x40\xe5\xc4\x45\x7f\x86\xc8\xb3\xde\x2c\xf4\x29\xd2\x4c\x46\x82\x15\x0a\xa4\x88\xe6\xc4\xc7\x21\xf4\x27\x7a\x1f\xac\x39\x5f\xa7\x48\x0b\x66\x03\x1d\xdd\x17\xa6\x6c\x29\xc3\x9f\x7e\x2e\x51\x6c\xc3\xab\x60\x12\x4c\xdc\x47\x90\xb1\x3c\xf8\x49\x92\xc5\x2c\xb4\xf8\x1a\xcc\x3a\x0d\x5b\x95\x79\xa4\xc3\x1f\x90\xe5\xf2\xb7\x5c\x64\x30\x28\xb8\x54\x9f\x45\x3a\x02\xbd\x17\xde\xbf\x1b\x41\x86\x52\xd2\x35\x0e\xbd\x14\xce\x03\xbd\xe0\x60\x77\xd6\xeb\x41\x29\xd2\x29\x21\xf0\x1c\xfc\x2c\xdd\xa9\x8d\x72\xda\xd7\x3d\x7d\xf3\x1d\x53\x45\x3f\x99\x3e\x9d\x2c\x36\x7d\xd3\xf3\x01\x79\xa6\x27\xdb\x95\x86\x81\x3e\x70\x68\xca\x7e\xc1\xc1\xd0\x00\xc9\x32\x8a\x50\xca\xa9\x27\x72\x30\x34\x8b\x5a\x22\xd6\xa8\x06\x67\xbd\x5e\x0f\x48\x68\xd2\xbb\x2d\x19\x99\xcf\x5d\x3b\xd9\x03\x6d\x4f\xcf\x1d\x07\xd5\xc8\xcf\xd6\x3b\xba\x07\xf6\x1b\x85\xe0\xa2\x59\xe2\x3e\x11\x23\x90\x8a\xaa\x52\x8e\xec\x58\xb3\x28\x4d\x51\xa8\x01\x31\xbd\x10\x97\x82\xe5\x6b\x43\xbb\x16\x5e\xc6\xa4\x8e\xa2\xa7\xa0\x19\xba\x4f\x44\x20\x50\x16\x3c\x97\xf8\x09\xef\x95\x5b\xcf\x09\xb0\xaa\x1d\x4a\x2d\x7d\x1a\xc7\x6f\xad\x71\x0d\x56\x22\x1b\x42\x23\x6b\x2d\x46\xcd\x27\x90\x50\xa6\x5c\xdd\xe8\x95\x94\x61\x15\xce\x07\xfd\x67\x7d\x2d\x3e\x91\x1d\xca\xae\x46\x3d\xd0\xa2\xd6\x18\xe1\xd8\x8f\x40\x59\xa6\x0a\xe6\x46\x21\x8e\xca\x0e\xc0\x70\x6f\x5e\xe0\x94\x32\x68\x94\x02\x56\x40\x4e\x3a\x09\xa6\x29\x27\xc3\xeb\xbd\x79\xd5\x01\xa2\x88\x67\x45\x8a\x0a\x3b\x98\xe0\xec\xd1\x79\x46\xfc\xa7\x96\xef\xbf\xce\xad\xd6\x20\xa1\x12\x78\x14\x95\x42\x60\x1c\xf4\x8f\xd0\x73\x6d\x1b\x67\x4e\xd4\x02\x55\x29\x72\x58\xd1\x54\xe2\x75\x18\xba\xec\x40\xf1\x42\x82\x4a\xd0\xea\x79\x25\x78\x06\x34\x52\x25\x4d\xd3\xad\xb1\x79\x96\xaf\x0f\x74\x59\x2a\xfe\x11\x57\x02\x65\x32\x60\xf1\x70\xe7\x17\x90\xa8\x3e\xb1\x0c\x79\xa9\x06\x7b\x06\xed\x15\xc9\xe2\x61\x90\x72\x1a\x0f\x62\x1e\x95\x19\xe6\x2a\xf8\xfc\xf1\x03\x3c\x07\xe8\x83\x1f\x37\x2a\xda\x5b\xc1\xbb\x94\x6a\xa4\x6b\x0c\x17\x17\xc3\xda\xa3\x34\xbb\x1b\x95\x29\xc5\xfc\x85\xe1\x66\xa0\x1d\x5f\xbd\x9b\xd9\xca\x7c\xc3\x7c\x0e\x44\xa7\xfc\xc4\x93\x44\x5a\xf5\x02\x32\x0c\x90\x46\xc9\xe0\xc8\x46\x64\xab\xc1\xaf\xce\x07\x5a\x58\xc3\x80\x2a\x25\x06\x44\x8a\x88\x0c\x0d\x40\x0f\x0e\x47\x46\x75\x9f\xb6\x38\x0f\x7d\xed\xd1\x55\xba\x51\xd9\xef\xf3\x01\xd1\x95\x08\x32\x0c\xf4\xf6\xd0\xe9\xeb\x80\xd4\x55\x09\xd2\xb0\x0d\x98\x4a\x84\x5d\x77\x8a\xc0\x8c\xdf\xe1\x03\xb3\xfc\x0e\x1b\x90\x67\x96\x51\x3b\x1e\xdc\xd1\xd4\x4a\xc8\x43\xa6\x3c\xa2\xe9\x8d\xe2\x82\xae\x31\x90\xa8\xde\x2b\xcc\x06\x64\xe9\xc5\x49\x46\x50\x83\x6b\xa4\xe7\xb5\xf6\x34\x11\x34\xde\x76\xa5\xe6\x4d\xa1\x51\x47\x07\xff\xfa\x10\xff\x10\xfe\xf1\x0f\x20\xa6\xfa\x62\xc9\xd7\xe2\x69\x2b\xd7\x9c\x5b\x37\xe5\xf2\x0d\xbf\x47\x39\x58\xf2\x7b\xed\xb5\x4d\xba\xff\xfe\x5d\xe3\xb5\x07\x24\xd0\xae\xc9\xf7\x07\x85\xe0\xc5\x80\xb8\x33\x8f\x8c\xbc\x2f\x36\xd3\x87\x01\x93\x03\xe2\x0f\x44\xab\x9f\xe3\x58\xa2\x84\xe6\x6b\x1c\x0c\xdb\x87\x58\xf8\x8d\x81\x3b\x76\xde\x92\x61\x10\x63\x8a\x6b\xaa\x70\x40\x0e\xce\x5e\x1d\xc2\x8c\x80\x58\x9c\x64\x04\xdd\x3d\x6e\x52\x20\x2a\x6c\xc3\xc3\xc3\xdc\xdb\xd3\xc8\x0e\xe4\xa8\x93\xef\x0f\x4c\x2a\x98\xd7\x50\x41\x41\x85\xf6\xad\xc3\x20\xc7\xfb\xe6\x97\x9b\x62\x13\x8e\x1f\xea\x89\x6f\x1b\xdc\x0d\xb6\x60\xc5\xf2\x78\x40\xf6\x03\xa2\x7d\xf2\xbd\xa4\xec\xbf\x6c\x35\xa8\x49\xd8\x93\xa8\xe7\xc8\xb9\x9d\x53\x34\xec\xab\x09\x94\x28\xd1\x2f\x52\x3d\x4c\xff\xc1\x5c\xe3\xdb\xea\xc9\xc3\xeb\x6f\xc2\x33\x13\x70\xb8\x68\x40\xf7\xce\x42\x5b\xc5\x35\x6d\xbd\x95\x16\xb5\xdf\x9c\xb1\x6c\x6d\x83\x11\x03\x83\x82\x80\x09\x55\xe6\xc4\x66\xe8\x2f\x26\xba\xc4\xe7\x73\xf3\xc9\x77\x2f\x09\x84\x8b\xb3\xde\x6e\xc7\x56\x56\x11\x9f\x8b\x98\x2a\x84\xaa\x3a\xeb\xcd\x62\x76\x07\x2c\x9e\x93\xd2\xf4\x91\x85\xa5\x69\x96\xbc\x58\xfc\x80\x1b\xc8\x9a\xda\x31\xf8\xf2\x14\xbd\xa3\x2c\x35\x35\xd9\x19\x85\x44\xe0\xaa\x09\x8a\xd6\x4c\x25\xe5\xd2\xc6\x42\x69\x8a\xb9\xc2\x28\xc9\x79\xca\xd7\xdb\xb0\x85\x29\x14\x68\x2a\x3c\x32\x8c\xf9\x26\xd7\x7e\x36\xdc\xed\xd6\xa8\x3e\x50\x85\x52\xfd\xc5\x2e\x53\x55\x76\x8a\xd9\x7e\xe2\x8b\x01\xf8\x93\xac\x2a\xdb\x7a\x2d\xa2\xa4\xaa\xc8\xe2\x9d\x43\x00\x3f\xf0\x0d\xcc\x42\xba\x98\x85\xc9\x0b\x1d\x58\x85\x31\xbb\x33\x3c\x63\x1e\x77\xf8\xcc\x30\x2f\x6b\x2e\xf5\x59\xb2\x38\xeb\xf5\x66\xb6\x44\x04\x36\x8a\x97\xf6\x68\x37\xe0\x3f\x97\x4c\x8d\xed\x28\x01\x53\x3c\x9f\x93\x3f\x97\x4c\x75\x24\x43\xf3\xd8\x1c\x50\x20\x68\x1e\xf3\x8c\xfd\xa2\xe3\x91\x86\x7a\x49\xcc\xa1\x45\xcd\x16\x9a\x93\x50\xe3\x24\x0b\x8d\x65\x16\x5a\xd4\x0f\xd3\x90\x30\xa9\xb8\xd8\xee\x93\x71\x93\xf0\x0d\x6c\x12\x16\x25\x60\x97\x01\x7d\x34\x80\x0e\x4e\x7c\x72\x81\xb1\xa1\x6d\x93\x60\xbe\x47\x83\xc3\x49\x16\x1f\xb9\xb2\x01\xda\xef\x6d\xcf\xd3\x48\x92\x51\x82\x71\x99\xe2\x3e\x4d\x1f\xb4\x2e\x6c\x51\x59\x82\xb6\x15\x88\x68\x8a\x79\x4c\xc5\x14\x8c\xcd\x09\xed\x51\xe4\x08\x36\x88\xb7\x31\xdd\x4a\x43\x5f\xc2\x4b\xb1\x2f\x24\xbf\x02\x59\xdc\xb8\x56\x8b\x32\xbb\x41\x9c\xf6\xbc\xae\x6b\x15\xb7\x6f\x25\xbc\xaa\x5f\xdb\xbe\xa9\x9b\x6b\x77\x6a\x07\xda\x76\x35\xd2\xdd\x30\x15\x25\xa0\xb8\x09\x3a\xac\x80\x47\x35\x6b\x9a\x6a\x73\x6f\x02\x7c\x05\x34\xe7\x2a\x41\x01\x6f\x38\x15\xf1\xef\x68\x86\xbf\x43\xbc\x05\x87\x97\x00\xcf\xdf\x1a\x3f\x3a\x27\xae\x1a\xac\xcf\x19\xc3\x65\x3f\x74\x40\xdf\xeb\x8a\xf1\xbc\xff\x1c\xf3\x88\xc7\xf8\xf9\xe3\xfb\xb7\x3c\x2b\x78\xae\x3d\xa5\x76\xa9\xfa\x28\x2c\x71\xa8\x79\xe9\xed\x76\xe7\x26\x94\xca\x15\x4c\xe7\xe0\x9a\x8e\x3b\x6d\xe8\xbd\xdd\xce\xc8\x18\xd6\xe8\xbb\x65\x55\xcd\x78\x61\x94\x6c\x10\xcd\xc9\x6e\x17\x54\x15\x31\x0e\x01\x7f\x86\x00\x3c\xca\xaa\x72\x3e\x0c\xb5\x8e\x5d\x8b\xb8\x4d\xb4\x30\xb3\x66\xa1\x45\xb5\xa8\xb7\x96\x55\x86\x85\x6e\x6f\x2c\xa8\x75\xe9\xb8\x7c\x1d\xc7\x04\x32\x54\x09\x8f\xe7\x44\xc7\x6c\xa4\xab\xae\x38\x1e\xeb\x79\xb5\x37\xf3\x19\xb2\x2d\x51\x1a\xee\x67\xc6\xcb\x3b\x83\xd4\xd5\x44\x02\x46\x74\x35\x12\xfd\x45\xa0\x48\x69\x84\x09\x4f\x63\x14\x73\xa2\xfd\x98\x1b\x36\xc0\x24\x3c\x6d\xdb\x4e\xf9\xaf\xe3\xf8\x61\xad\xda\xf2\x3f\xd3\x46\xbe\xc9\xf7\xad\x63\x04\xde\x78\x1b\x3b\x21\x0b\x8d\xd3\x29\xe4\x51\x4b\x5e\x0a\x08\xdb\x16\xdd\x5c\xd1\x79\x7b\xde\xed\x0c\x05\x12\x4d\xb2\x73\x63\xd2\xa4\xaa\xaa\x27\xb8\xfe\xb1\xcd\x9f\x1a\xa3\x66\x79\x84\xb0\xdb\x05\xa6\x11\xe8\x3c\x93\x2a\x20\x97\x17\x17\xaf\xc6\x17\x93\xf1\xc5\x25\x4c\x5e\x4e\x2f\x5e\x10\xed\x5a\xbb\x7c\x1b\x0a\xa6\x66\xaa\xa2\x0a\xab\xca\x18\x4f\xf0\x59\xa2\xd0\x32\xad\x2a\x18\xec\x76\xad\xcf\xa1\x33\x0f\x0b\xf6\x0e\x15\x65\x69\x55\x19\x04\xfe\xc3\x41\x58\xa6\x3b\xd6\xe4\x98\x5b\xae\xd7\x07\x8c\x2d\xd7\x6b\x5d\x43\x5a\xad\x58\x54\x73\x65\xd7\xf8\x40\xa5\xfa\x0f\x9d\x70\x54\x95\x6e\xc2\x8a\xb2\xb4\x14\x08\x54\xe9\x45\xeb\xd1\xd7\xea\x24\xdb\xd3\x8b\x97\xc4\xd1\xd8\x42\xe6\x28\xdb\x17\x88\xc0\x9f\x4b\x94\x4a\x1a\xf0\xaa\xaa\xf7\xd2\x1b\x81\xf4\x16\x05\x10\x5e\x60\x4e\xaa\x2a\x80\x1f\x69\x29\xb5\x23\x5e\x29\x14\x20\xb0\x40\xaa\x30\xf6\xe4\x49\x28\x73\xc5\x52\x8d\xe4\x4f\x05\xe6\x9f\xf5\x47\x4d\x60\x8b\xaa\xd3\xc2\xda\xdb\x6b\xd6\xda\x8e\x6c\x33\x6b\x43\x66\x83\x69\xfb\xf7\xaa\x9a\xc2\x43\xdb\xaa\x74\x50\xc4\xa4\x3a\x2b\x1e\x95\xd2\xed\x1e\x6b\xa1\xbd\x1f\xa9\x94\xfa\xb6\xe7\x10\x4d\xe1\x46\x3c\xaa\xe6\x9b\xc5\xcd\xd7\x78\xc5\x30\x8d\x49\x17\xe9\xec\x57\xe3\x31\x74\x37\xa8\x3f\x67\x78\xfe\x56\x5f\xed\x38\x76\x06\xc3\x36\x6f\xfb\xe7\x23\xbd\x43\xe3\xbc\xcd\x28\xb0\xdc\x9c\x2f\xc6\x0d\x9a\x48\x3f\xdd\xea\x13\x07\x56\xa5\xd2\x66\x52\x4a\x7d\xd4\xe8\x29\x1f\x34\x78\xbd\x45\x61\x3c\x3e\x74\x17\x5f\x41\xcd\x07\xbe\x06\x96\x2b\x0e\x4b\x6d\x46\x6b\x9a\xe1\x1a\xf1\x56\x87\x4a\x2e\x7c\xa0\x42\x9d\x8c\x1f\x16\x5d\x9a\x34\x3d\x75\xe5\xe8\xd1\x44\xc7\x27\x0c\xfd\x67\x5d\xa1\xf7\x87\xc1\x2d\x6e\xcd\x35\x5d\x33\x01\x5d\xbe\xc8\x56\x03\xd4\xc3\x6f\x79\x8c\xf3\xf9\xe4\x6a\x78\xd6\x6b\x21\x6a\x73\xd8\x1f\x06\xe6\xb6\x6d\xd0\xca\x1d\x6d\xb3\x32\xe9\x51\x2b\x3f\x72\x52\x6a\xd5\x8e\xea\x02\x96\xad\x60\xf5\xad\xf9\xf6\x6d\x01\x69\xaf\x7c\x55\xd7\xaa\xfc\xfa\x5a\x9f\xfd\x4e\xc1\x65\x8f\x00\xfd\x13\x7e\xd3\xc9\xd2\xfb\x3a\x3c\x1a\x4f\xfa\x06\xa0\x77\x38\x72\x79\x72\xe4\xea\xe4\xc8\x8b\x93\x23\x2f\xfb\x26\xb6\xef\xd9\x94\xac\x15\xe1\x7b\x1b\x6f\x6f\x18\x7f\x06\xb9\x33\xba\xb1\x45\x1d\xbe\x5b\x33\xec\x9c\x16\xd6\x53\xda\xc3\xfe\x5c\x2f\xa7\x03\x82\x35\xaa\x9b\x94\xeb\x23\xdf\x41\xb3\x95\xa0\x19\xba\x74\x41\x43\x85\xbb\x9d\x81\xae\x2a\x17\xca\x69\x42\xeb\xbe\xb1\x3b\x74\xf7\x52\x0a\x5d\x7d\xbf\xf6\x09\xc5\xab\xe2\x9e\x80\x41\xfb\xc6\x14\x86\xe7\xe4\x42\x57\x35\xed\x4a\x35\x61\xd6\x45\x1d\x09\xcf\x0e\xde\x80\x10\x58\x1c\x23\xb6\x0d\xd7\xc1\xef\x30\xb6\x50\x6a\xda\xa5\x3f\x1a\x4d\x50\x6e\x4a\x01\x47\xa2\xbd\x56\x85\xa1\x15\x98\x75\xca\x00\xfb\x11\xd7\x5e\xec\x64\xf3\xff\xc5\x8d\xfe\x55\x07\x43\x87\x60\xa6\x5a\xb3\xf8\xc4\x74\xdc\x5a\x03\x1d\x0b\x95\xcc\xeb\x15\x83\xa0\xeb\xc8\x5b\x45\xc6\x23\xde\xdc\x68\xcd\x5a\x8c\x0b\x9a\x78\x6e\xa1\xe7\xa4\x55\xc3\xec\xef\xc3\xf5\x2d\x4f\x76\x65\xa1\x9b\xbd\x07\x6d\xa8\xd7\x9b\xa9\x64\xa1\x3f\xa1\xb6\x11\xef\x0a\xbc\x5c\xad\x7f\x97\x4a\x50\x85\xeb\x6d\xcb\xbc\x9c\xeb\xfb\x3d\xdf\x34\x41\xb4\xf1\xb9\xb6\xa4\xa7\x91\x32\x09\x51\xc2\x25\xe6\x86\xac\x36\x31\x9e\x12\x8b\x96\xe1\xb1\x28\xf6\x5c\xea\x65\xfc\xd9\x7b\x2e\x61\x60\xa4\xe6\x28\xb1\x1c\x0d\xab\xca\x47\xb2\x4d\x20\xab\x27\x1e\x46\xb2\xbd\x7d\x05\xe9\x2d\xab\x12\x27\x25\x0f\x64\x61\xac\xf0\x8e\xca\x91\x8d\x4e\xcb\x32\x5e\xd4\xcb\x68\xe3\x35\xe5\x9b\x39\x39\x5a\xa1\x39\xb2\x3b\x9d\x90\x66\x65\x6a\x1b\x06\x4f\xca\x9c\x29\x01\x98\xc1\xb6\x4f\xa9\xcb\x21\x36\xf1\xd7\x61\xbd\x6b\x1c\xdd\xfc\x96\xf1\x31\x4d\xd3\x31\x15\x82\x6f\x48\xb8\x98\x99\x42\xcb\xe2\x04\xd6\x07\x71\xb4\x77\x58\xb7\x34\xd6\x3f\x39\xa7\x3f\xda\x1f\x8b\xb4\x2e\xb9\xd8\xf6\x87\x9a\x1a\x7d\x21\xa4\x6f\x50\xec\x2f\x47\x9b\xf9\xa5\x0d\xeb\x71\x86\x16\xb3\xe5\xe2\xc6\x74\xc2\xeb\x34\xd5\x41\x2b\x53\x98\xdd\x94\x19\x9c\x57\xd5\x70\x16\x2e\x6b\xac\x60\x24\xdc\x68\xf5\x16\xb7\xa3\x73\x63\x79\x5a\xaf\xe7\x55\xe5\x00\xac\x32\x1a\xf9\x5b\x47\xf6\x54\x69\xed\x76\xc1\x27\xc1\xb2\xbf\x26\x4c\xe1\x8d\x79\x32\xa4\x17\xaa\x2a\x47\xee\x11\xb5\x7d\x85\x4a\x4e\x2d\x42\xbc\xfd\x9d\x10\xf9\xd3\x15\x78\x6a\x85\xfe\xe8\xa9\x90\xe3\x6c\xf9\x55\x1a\x7e\x44\x80\x5a\xdf\xbb\x9d\xed\xd2\xda\x4e\x31\x07\xab\xc5\x3d\x75\x9f\xf5\x6a\xe5\xf9\xdd\xd5\x52\x7e\xb6\x34\x4a\x77\x13\xdd\xe8\xfe\xce\xfb\x97\x55\x7f\x9e\x2d\x83\xf7\x71\xa3\xec\xaf\xd0\xb6\xf3\xbd\x29\x57\x2d\xbf\xdb\xf8\x47\xb7\xc0\x23\xeb\x9f\xb2\x83\xdd\xce\x72\x7c\x52\x63\xce\xf7\x1a\x3c\xf2\xc6\x79\x59\x38\x67\x55\xe5\x18\x70\x5e\xf3\x6b\xd4\xba\x27\x9c\x85\xa9\x7a\x3a\x3a\x5b\xef\x20\x89\x8d\x16\x1a\x3a\xb2\xf5\xe7\x8f\x1f\xaa\x0a\x76\xbb\xbd\x4f\x4c\xa5\xce\x53\x7d\xad\x72\x9b\xc8\x80\x16\xf2\x8e\x06\xa5\x0c\x37\xc5\xd8\xdd\x29\x87\x65\xa1\x0b\x8a\x32\xd4\xc5\xf1\x68\xfb\x85\x4a\x9d\xd2\x6b\xe8\xf0\xe2\xea\x72\x19\xe3\x55\xf4\x32\x76\x79\xf5\x17\xfd\xa8\x30\x28\xf2\xb5\x0f\x77\x8c\xbe\x0e\xe8\x34\xa1\x80\x09\x5e\xc7\x5d\x5a\x75\x74\xe0\x09\xdc\xed\x0e\x7b\x2c\xc5\x5d\x46\x5a\x2b\x81\x11\x6a\x93\x05\x6b\xb0\x77\x68\x43\x4c\x53\x41\x25\x8b\x7a\xa9\x1f\x4c\x56\xbe\xdb\xb5\xdb\x6d\xf4\x9d\x79\xed\xdc\x5d\x0f\x7e\x34\x57\x38\x71\x55\xc1\x8c\x2d\x06\xf6\x42\x27\xb6\x77\x70\x85\xe0\x2b\x96\xe2\x70\x16\xb2\x45\x9d\xa3\x1a\x25\xd7\xb3\x7f\xa4\x6b\xb4\x3a\xa8\xeb\xc5\xbb\x5d\x67\x80\x80\xa2\x62\x8d\x6a\x4e\xbe\x2c\x53\x9a\xdf\xd6\x91\x83\x4e\x89\x5b\xa1\x43\x41\xd7\x08\x3c\xef\x96\x64\xc8\xe2\xd7\xcf\xbe\x7b\xf9\x9b\xdf\x5c\xeb\xf2\x6f\x4d\x82\xf3\xe1\x9d\x5d\x93\x97\xd9\x12\x05\xe9\x2a\xc7\xbe\x91\xf5\x1b\xc9\x7e\x1d\xdf\x23\x9d\x5d\xf5\x57\x03\x68\x4c\x9d\x40\xc6\x72\x1d\x03\x83\x54\x58\xcc\xc9\x45\x30\xa9\x19\xf8\x88\x29\x55\xec\x0e\x41\x5f\xa5\x44\x08\x7c\x65\x63\x1f\xcb\xcf\x12\x75\xa2\x27\x13\x5d\x43\x62\xb9\x0d\x88\xea\xb5\x47\x60\xca\x06\xcb\xad\x11\x40\x8c\x2b\x5a\xa6\x0a\x2c\x81\x18\x83\x0f\xb7\x6c\xea\xdc\x33\xa1\x8a\xf1\x47\xce\x71\xd5\x21\x8d\x0f\x58\x6a\xaf\xd6\x6a\xb6\xc1\xea\x6e\x0b\x6e\x83\x76\xdd\xed\x62\x96\x07\x03\x20\x9f\x2d\xf8\x97\x22\xe6\x4d\x8b\x8d\x56\x66\x2a\x7e\x60\xd4\xcc\x7f\x38\xb7\x96\xe5\x52\x87\x50\xba\x28\xe2\x33\xec\x76\x54\xeb\xe0\x17\x36\xfa\x05\x0d\x0a\x6f\x13\xce\x22\x94\xed\x84\xd9\xae\xf4\x68\xd2\x75\x88\xa4\xc9\xbe\x1a\x61\xd4\x39\x58\xef\xec\x11\x06\xeb\x19\x2a\x5e\x1c\xbd\xf8\xb7\x51\xbf\x61\x8a\xde\xe1\xd8\x96\x10\x49\x2b\x0d\xa0\x77\xf8\xa3\xed\xb4\x24\xb4\xa9\xc7\x98\xd5\xa6\x6b\x67\x8e\x9b\x0a\x67\xef\x71\xb9\x1a\x99\xf6\x5b\x6b\xf4\x47\xd0\x6f\xd1\xd1\x1f\xf5\x6d\x3f\xe8\xce\x58\x27\x0f\x26\x2d\xa5\x12\x6c\x7f\x2d\x61\x38\xce\x5c\x2d\x27\x2f\x87\x03\xdb\x69\xd5\x31\xf4\x4f\xfb\x19\x8c\xd7\x3a\x0c\xfc\x25\x76\xbb\x5e\xa0\x7f\xcc\xab\x97\xfd\xe7\x18\x76\xe8\xa0\x78\xa0\x7f\xea\xc7\x2e\x07\xe9\xd3\xc1\xbb\x0d\x3b\xe1\xf8\xc3\x97\x3d\x52\xda\xb4\xd8\x17\x30\xdf\xb7\x73\xd7\xb9\xe6\xe3\x79\x64\xad\xe9\xb9\x5d\x54\xe9\x6b\xc0\xb3\x5e\x97\xd8\x35\xaa\xbe\xed\xb3\xf5\x0b\xff\x98\xa0\x6e\x1e\xbe\x90\xf1\xe4\xb8\x27\x1f\x7a\x25\x70\x2b\x41\xc4\xcb\x34\x86\x9c\x2b\x58\xa2\xd5\xdf\x03\x6f\x62\x9a\x4b\xff\x5e\xbb\x7a\x62\xca\x16\x2e\x29\x72\x8a\xf2\x59\xac\xcf\x5b\xbd\xab\xe8\x94\xab\x69\xec\x2c\x48\x92\xc5\x89\x32\x25\x8d\x5b\x66\x3d\x4b\xae\x16\xf6\xd3\xed\x3d\x9e\xaf\xd8\xba\x14\x46\x80\x72\x16\x26\x57\x06\xca\x2f\xd0\xfa\xb3\x05\x53\x3c\xe8\xd6\x42\xee\x5c\xe2\x65\x11\xca\xc6\x67\x3d\x21\x92\xf2\xfb\xaf\x71\xf6\x77\xf6\x1c\x3d\xbf\x6b\x1f\x2b\xfe\x20\x0b\x2d\xbc\xbd\xbf\x71\xc0\xed\xfb\xb9\x11\x08\xd4\x63\x23\x88\xcb\x22\xd5\x06\x81\xc0\x05\xc4\x98\xa2\x72\xef\xde\xdd\x8a\x8b\x8c\xe6\x74\x8d\xe6\x0e\xd3\xd6\x41\x6b\xb6\xbc\xdf\xad\x9d\x72\x53\x28\x75\x1d\x07\x81\xe7\x9e\x5b\x33\xd7\x72\x75\x7c\xe6\xe4\x42\x1c\x0e\x87\xf5\xd8\x4d\x44\xf7\x2a\xe2\xb8\x26\xf5\x43\x8c\x5c\xa1\xb8\xa3\x29\x81\x23\x95\x0a\xe6\x06\x9b\xd2\xf3\x47\x57\xe5\x34\xca\x05\x3f\x0e\x83\x8c\xe5\xa5\x42\x39\x3c\xac\x25\xfb\x93\xdb\xea\x88\xd5\xcb\xe9\x83\x77\xe2\x99\x9c\x90\x45\x23\x98\x47\x5d\x9f\xa7\xd9\x1d\x2b\xee\xf8\x38\x75\x33\xd3\x75\x55\x07\x9e\xaa\x8d\x0c\xfc\x5b\xa2\xc6\x43\xb4\x7d\x55\x4b\x5a\xf5\x2b\xb8\x3d\x5f\xd5\x75\x55\x5d\xf9\x1d\xf8\xa9\x87\xdc\xd4\x29\x87\x79\xcc\x49\x79\xaa\x9e\x6b\x63\x1c\x9d\x1d\xf7\x4c\x5d\xd7\xe0\xbf\x6b\x4f\xb1\xef\x28\xda\xa5\xbf\xe3\xc6\xe3\x0b\xde\x78\xac\xc6\x65\x07\x1b\xbb\x79\x5c\xa9\xd6\xb2\x9c\x4a\x61\xe1\x0d\x0d\xf5\x5f\x0c\x7d\xa5\x6a\x1b\x94\x0f\x2b\xb6\xe1\xe4\x69\x6a\x6d\x33\xf7\xff\xa6\xd4\x8f\xcd\x7d\xc2\xf3\xd6\x7d\xc2\xf3\x9c\x6f\xfe\x8f\x75\xec\xde\xac\x34\x0f\x55\xdc\x56\xfc\x97\x9b\x2b\xce\x15\xea\xf0\xe0\xc8\xd3\x13\x7d\xd7\xb6\xc6\xe6\x85\x88\x9b\xd7\xfb\xef\xff\x82\xcb\x8b\xc9\xb7\x70\x43\xb3\x12\x53\x5d\xd8\xc1\x7c\x64\x7f\xc1\xa7\xfa\x09\x0a\xdc\xb8\xbf\x30\x92\x2d\xb7\xd6\x7e\xc1\xa2\x5f\xf5\x76\x5f\xad\x04\xfe\x8f\x92\x24\x59\x3c\x06\xa1\x3d\xf7\x99\xb7\x2c\xcb\xc3\x2c\xb4\x7f\x94\x79\xf6\x3f\x03\x00\x3d\xf8\x57\xbd\x9d\x39\x00\x00")
 
 func webpageHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -104,7 +104,7 @@ func webpageHtml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "webpage.html", size: 17777, mode: os.FileMode(420), modTime: time.Unix(1495387991, 0)}
+	info := bindataFileInfo{name: "webpage.html", size: 14749, mode: os.FileMode(420), modTime: time.Unix(1792276390, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
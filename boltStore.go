@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"go.etcd.io/bbolt"
+	"time"
+)
+
+// boltFile is the database the bolt storage keeps in appDir, and in the
+// directory of every further account.
+const boltFile = "microBadger.db"
+
+var (
+	selectionsBucket = []byte("selections")
+	presetsBucket    = []byte("presets")
+	historyBucket    = []byte("history")
+	scheduleBucket   = []byte("schedule")
+	sessionBucket    = []byte("session")
+
+	// Selections, history, schedule and session each live under this key of their
+	// bucket. Presets are keyed by name.
+	currentKey = []byte("current")
+)
+
+// boltStore keeps everything in a single bbolt database. Each value is the
+// same JSON the file storage writes, and every save is one transaction.
+type boltStore struct {
+	db *bbolt.DB
+}
+
+func openBoltStore(path string) (*boltStore, error) {
+	// bbolt locks the file, so a second microBadger using the same
+	// database fails after the timeout instead of waiting forever.
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range [][]byte{selectionsBucket, presetsBucket, historyBucket, scheduleBucket, sessionBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltStore{db: db}, nil
+}
+
+func (s *boltStore) get(bucket, key []byte) ([]byte, error) {
+	var value []byte
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		// Get's result is only valid during the transaction
+		if v := tx.Bucket(bucket).Get(key); v != nil {
+			value = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err == nil && value == nil {
+		err = errNotFound
+	}
+	return value, err
+}
+
+func (s *boltStore) put(bucket, key []byte, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucket).Put(key, value)
+	})
+}
+
+func (s *boltStore) getMB(bucket, key []byte) (*mbFile, error) {
+	value, err := s.get(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMBFile(value)
+}
+
+func (s *boltStore) LoadSelections() (*mbFile, error) {
+	return s.getMB(selectionsBucket, currentKey)
+}
+
+func (s *boltStore) SaveSelections(f *mbFile) error {
+	return s.put(selectionsBucket, currentKey, f)
+}
+
+func (s *boltStore) Presets() ([]string, error) {
+	presetList := make([]string, 0)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(presetsBucket).ForEach(func(k, v []byte) error {
+			presetList = append(presetList, string(k))
+			return nil
+		})
+	})
+	return presetList, err
+}
+
+func (s *boltStore) LoadPreset(name string) (*mbFile, error) {
+	return s.getMB(presetsBucket, []byte(name))
+}
+
+func (s *boltStore) SavePreset(name string, f *mbFile) error {
+	return s.put(presetsBucket, []byte(name), f)
+}
+
+func (s *boltStore) RenamePreset(name, newName string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(presetsBucket)
+		value := bucket.Get([]byte(name))
+		if value == nil {
+			return errNotFound
+		}
+		if bucket.Get([]byte(newName)) != nil {
+			return errPresetExists
+		}
+		// value belongs to the transaction, which Put may invalidate
+		err := bucket.Put([]byte(newName), append([]byte{}, value...))
+		if err != nil {
+			return err
+		}
+		return bucket.Delete([]byte(name))
+	})
+}
+
+func (s *boltStore) DeletePreset(name string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(presetsBucket)
+		if bucket.Get([]byte(name)) == nil {
+			return errNotFound
+		}
+		return bucket.Delete([]byte(name))
+	})
+}
+
+func (s *boltStore) LoadHistory() (rotationHistory, error) {
+	history := rotationHistory{}
+	value, err := s.get(historyBucket, currentKey)
+	if err != nil {
+		return history, err
+	}
+	err = json.Unmarshal(value, &history)
+	return history, err
+}
+
+func (s *boltStore) SaveHistory(history rotationHistory) error {
+	return s.put(historyBucket, currentKey, history)
+}
+
+func (s *boltStore) LoadSchedule() (schedule, error) {
+	entries := schedule{}
+	value, err := s.get(scheduleBucket, currentKey)
+	if err != nil {
+		return entries, err
+	}
+	err = json.Unmarshal(value, &entries)
+	return entries, err
+}
+
+func (s *boltStore) SaveSchedule(entries schedule) error {
+	return s.put(scheduleBucket, currentKey, entries)
+}
+
+func (s *boltStore) LoadSession() (sessionInfo, error) {
+	var session sessionInfo
+	value, err := s.get(sessionBucket, currentKey)
+	if err != nil {
+		return session, err
+	}
+	err = json.Unmarshal(value, &session)
+	return session, err
+}
+
+func (s *boltStore) SaveSession(session sessionInfo) error {
+	return s.put(sessionBucket, currentKey, session)
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
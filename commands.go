@@ -0,0 +1,540 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const commandUsage = `Usage: microbadger [flags] <command> [arguments]
+
+Commands:
+  sync                     Scrape the profile and save the selections
+  list                     Scrape the profile and list the badges by category
+  assign <slot> <badgeId>  Show a badge in a slot
+  clear <slot>             Clear a slot
+  randomize --once         Randomize every slot once and exit
+  randomize                Randomize every slot each interval, like -headless
+  preset save <name>       Save the current selections as a preset
+  preset load <name>       Make a preset the current selections
+  preset list              List the saved presets
+  preset delete <name>     Delete a preset
+  preset rename <name> <new name>
+                           Rename a preset
+  preset duplicate <name> <new name>
+                           Save a copy of a preset under a new name
+  preset show <name>       List the badges a preset puts in each slot and
+                           how they differ from the current selections
+  schedule                 Show the schedule entries, the preset active now
+                           and the next changes
+  import                   Copy the files in the microBadger directory into
+                           the storage chosen with -storage
+  vault set                Store the login in the vault, encrypted with a
+                           passphrase
+  vault show               Unlock the vault and show whose login it holds
+  vault delete             Delete the vault
+  account list             List the accounts and their saved sessions
+  account add <name>       Add an account, kept in accounts/<name> in the
+                           microBadger directory
+
+Commands work on the default account, or on the one chosen with -account.
+Without a command microBadger starts the web interface. Run with -h for the
+list of flags.
+`
+
+// runCommand runs one of the scripting subcommands given after the flags and
+// returns the process exit code. Notifications are written to stderr and
+// command output to stdout.
+func runCommand(args []string) int {
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0)
+	// Send notifications to the log as in headless mode
+	*headless = true
+
+	switch args[0] {
+	case "account":
+		return accountCommand(args[1:])
+	case "help":
+		fmt.Print(commandUsage)
+		return exitOK
+	}
+	a, ok := getAccount(*accountName)
+	if !ok {
+		return usageError("There is no account " + *accountName + ". Add it with: microbadger account add " + *accountName)
+	}
+	switch args[0] {
+	case "sync":
+		return syncCommand(a, args[1:])
+	case "list":
+		return listCommand(a, args[1:])
+	case "assign":
+		return assignCommand(a, args[1:])
+	case "clear":
+		return clearCommand(a, args[1:])
+	case "randomize":
+		return randomizeCommand(a, args[1:])
+	case "preset":
+		return presetCommand(a, args[1:])
+	case "schedule":
+		return scheduleCommand(a, args[1:])
+	case "import":
+		return importCommand(a, args[1:])
+	case "vault":
+		return vaultCommand(a, args[1:])
+	}
+	log.Println("Unknown command " + args[0])
+	fmt.Fprint(os.Stderr, commandUsage)
+	return exitUsage
+}
+
+func usageError(message string) int {
+	log.Println(message)
+	fmt.Fprint(os.Stderr, commandUsage)
+	return exitUsage
+}
+
+// loginAndSync logs in, loads the saved selections and merges a fresh scrape
+// of the profile into them.
+func (a *account) loginAndSync() int {
+	if code := a.loginFromCredentials(); code != exitOK {
+		return code
+	}
+	a.loadSelections()
+	err := a.getMicroBadges()
+	if err != nil {
+		log.Println("Failed to sync microbadges: " + err.Error())
+		return exitFailed
+	}
+	return exitOK
+}
+
+func syncCommand(a *account, args []string) int {
+	if len(args) != 0 {
+		return usageError("sync takes no arguments")
+	}
+	if code := a.loginAndSync(); code != exitOK {
+		return code
+	}
+	if err := a.submitCheckedMicroBadges(a.state.selectedSlots()); err != nil {
+		return exitFailed
+	}
+	log.Printf("Synced %d microbadges", a.state.badgeCount())
+	return exitOK
+}
+
+func listCommand(a *account, args []string) int {
+	if len(args) != 0 {
+		return usageError("list takes no arguments")
+	}
+	if code := a.loginAndSync(); code != exitOK {
+		return code
+	}
+	categories := a.state.categoriesSnapshot()
+	categoryNames := make([]string, 0, len(categories))
+	for category := range categories {
+		categoryNames = append(categoryNames, category)
+	}
+	sort.Strings(categoryNames)
+	for _, category := range categoryNames {
+		fmt.Println(category)
+		for _, mb := range categories[category] {
+			fmt.Printf("  %-8s %s%s\n", mb.Id, mb.Name, selectedSlotsLabel(mb))
+		}
+	}
+	return exitOK
+}
+
+// selectedSlotsLabel lists the slots a badge is selected for, e.g.
+// " [slots 1, 3]".
+func selectedSlotsLabel(mb *microBadge) string {
+	slots := []string{}
+	for i, sel := range mb.Selected {
+		if sel {
+			slots = append(slots, fmt.Sprintf("%d", i+1))
+		}
+	}
+	if len(slots) == 0 {
+		return ""
+	}
+	return " [slots " + strings.Join(slots, ", ") + "]"
+}
+
+func assignCommand(a *account, args []string) int {
+	if len(args) != 2 {
+		return usageError("assign takes a slot and a badge id")
+	}
+	return a.assignCommandSlot(args[0], args[1])
+}
+
+func clearCommand(a *account, args []string) int {
+	if len(args) != 1 {
+		return usageError("clear takes a slot")
+	}
+	return a.assignCommandSlot(args[0], "")
+}
+
+// assignCommandSlot shows badgeID in the slot, or clears it when badgeID is
+// empty, and records the change in the rotation history.
+func (a *account) assignCommandSlot(slotID, badgeID string) int {
+	if !validSlotID(a, slotID) {
+		return usageError(fmt.Sprintf("Slots are numbered 1 to %d", a.state.SlotCount()))
+	}
+	if code := a.loginFromCredentials(); code != exitOK {
+		return code
+	}
+	a.loadHistory()
+	err := a.assignSlot(badgeID, slotID)
+	if err != nil {
+		log.Println("Failed to update slot " + slotID + ": " + err.Error())
+		return exitFailed
+	}
+	a.state.recordHistory(slotID, badgeID, time.Now())
+	if err := a.saveHistory(); err != nil {
+		log.Println(err.Error())
+		return exitFailed
+	}
+	if badgeID == "" {
+		log.Println("Slot " + slotID + " cleared")
+	} else {
+		log.Println("Slot " + slotID + " now shows badge " + badgeID)
+	}
+	return exitOK
+}
+
+func randomizeCommand(a *account, args []string) int {
+	flags := flag.NewFlagSet("randomize", flag.ContinueOnError)
+	once := flags.Bool("once", false, "Randomize every slot once and exit")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		return usageError("randomize only takes --once")
+	}
+	if !*once {
+		return runHeadless([]*account{a})
+	}
+	if code := a.loginAndSync(); code != exitOK {
+		return code
+	}
+	a.loadHistory()
+	for _, v := range a.randomizeBadges() {
+		if !v.Updated {
+			return exitFailed
+		}
+	}
+	return exitOK
+}
+
+func presetCommand(a *account, args []string) int {
+	if len(args) < 1 {
+		return usageError("preset needs one of save, load, list, delete, rename, duplicate or show")
+	}
+	if args[0] == "list" {
+		if len(args) != 1 {
+			return usageError("preset list takes no arguments")
+		}
+		for _, name := range a.getPresets() {
+			fmt.Println(name)
+		}
+		return exitOK
+	}
+	if args[0] == "rename" || args[0] == "duplicate" {
+		if len(args) != 3 {
+			return usageError("preset " + args[0] + " takes a preset name and a new name")
+		}
+		var err error
+		message := ""
+		if args[0] == "rename" {
+			err = a.renamePreset(args[1], args[2])
+			message = "Preset " + args[1] + " renamed to " + args[2]
+		} else {
+			err = a.duplicatePreset(args[1], args[2])
+			message = "Preset " + args[1] + " duplicated as " + args[2]
+		}
+		if err != nil {
+			log.Println(presetErrorMessage(err))
+			return exitFailed
+		}
+		log.Println(message)
+		return exitOK
+	}
+	if len(args) != 2 {
+		return usageError("preset " + args[0] + " takes a preset name")
+	}
+	name := args[1]
+	// savePreset checks the name of a new preset
+	if !storedPresetName(name) {
+		return usageError(errInvalidPresetName.Error())
+	}
+
+	switch args[0] {
+	case "save":
+		a.loadSelections()
+		if err := a.savePreset(name); err != nil {
+			log.Println(err.Error())
+			return exitFailed
+		}
+		log.Println("Saved preset " + name)
+	case "load":
+		if !a.presetExists(name) {
+			log.Println("The requested preset does not exist")
+			return exitFailed
+		}
+		// Errors reach the log through notify
+		if err := a.loadPreset(name); err != nil {
+			return exitFailed
+		}
+		log.Println("Loaded preset " + name + " as the current selections")
+	case "delete":
+		err := a.deletePreset(name)
+		if err == errNotFound {
+			log.Println("The requested preset does not exist")
+			return exitFailed
+		}
+		if err != nil {
+			log.Println(err.Error())
+			return exitFailed
+		}
+		log.Println("Deleted preset " + name)
+	case "show":
+		preset, err := a.store.LoadPreset(name)
+		if err != nil {
+			log.Println(presetErrorMessage(err))
+			return exitFailed
+		}
+		a.loadSelections()
+		for _, diff := range a.diffPreset(preset.Badges, a.state.badgesSnapshot()) {
+			fmt.Printf("Slot %s: %s\n", diff.Slot, strings.Join(diff.Badges, " "))
+			for _, id := range diff.Added {
+				fmt.Println("  + " + id)
+			}
+			for _, id := range diff.Removed {
+				fmt.Println("  - " + id)
+			}
+		}
+	default:
+		return usageError("Unknown preset command " + args[0])
+	}
+	return exitOK
+}
+
+func scheduleCommand(a *account, args []string) int {
+	if len(args) != 0 {
+		return usageError("schedule takes no arguments")
+	}
+	a.loadSchedule()
+	currentSchedule := a.state.scheduleSnapshot()
+	if len(currentSchedule) == 0 {
+		fmt.Println("No schedule entries. Add them on the Schedule page or with the API")
+		return exitOK
+	}
+	for i, e := range currentSchedule {
+		conditions := []string{}
+		if e.From != "" {
+			conditions = append(conditions, e.From+" to "+e.Until)
+		}
+		if len(e.Weekdays) > 0 {
+			conditions = append(conditions, strings.Join(e.Weekdays, ","))
+		}
+		if e.Start != "" {
+			conditions = append(conditions, e.Start+"-"+e.End)
+		}
+		if len(conditions) == 0 {
+			conditions = append(conditions, "always")
+		}
+		label := ""
+		if e.Name != "" {
+			label = e.Name + ": "
+		}
+		fmt.Printf("%d. %spreset %s, priority %d, %s\n", i+1, label, e.Preset, e.Priority, strings.Join(conditions, " "))
+	}
+	now := a.scheduler.clock.Now()
+	if active := currentSchedule.activeAt(now); active >= 0 {
+		fmt.Printf("Active now: entry %d, preset %s\n", active+1, currentSchedule[active].Preset)
+	} else {
+		fmt.Println("Active now: none")
+	}
+	for _, change := range currentSchedule.upcoming(now, 5) {
+		if change.Entry >= 0 {
+			fmt.Printf("%s: entry %d, preset %s\n", change.Time.Format("Mon 2006-01-02 15:04"), change.Entry+1, change.Preset)
+		} else {
+			fmt.Printf("%s: none\n", change.Time.Format("Mon 2006-01-02 15:04"))
+		}
+	}
+	return exitOK
+}
+
+// importCommand copies the selections, presets, rotation history, schedule and
+// session details saved as files in appDir into the storage chosen with
+// -storage. The files are left in place.
+func importCommand(a *account, args []string) int {
+	if len(args) != 0 {
+		return usageError("import takes no arguments")
+	}
+	if *storageName == "files" {
+		return usageError("import copies the files into another storage, e.g. microbadger -storage bolt import")
+	}
+	files := &fileStore{dir: accountDir(a.name), notify: a.state.notify}
+
+	selections, err := files.LoadSelections()
+	if err == nil {
+		err = a.store.SaveSelections(selections)
+	}
+	if err != nil && err != errNotFound {
+		log.Println("Error importing the selections: " + err.Error())
+		return exitFailed
+	}
+
+	presets, err := files.Presets()
+	if err != nil {
+		log.Println("Error listing presets: " + err.Error())
+		return exitFailed
+	}
+	for _, name := range presets {
+		preset, err := files.LoadPreset(name)
+		if err == nil {
+			err = a.store.SavePreset(name, preset)
+		}
+		if err != nil {
+			log.Println("Error importing preset " + name + ": " + err.Error())
+			return exitFailed
+		}
+	}
+
+	history, err := files.LoadHistory()
+	if err == nil {
+		err = a.store.SaveHistory(history)
+	}
+	if err != nil && err != errNotFound {
+		log.Println("Error importing the rotation history: " + err.Error())
+		return exitFailed
+	}
+
+	entries, err := files.LoadSchedule()
+	if err == nil {
+		err = a.store.SaveSchedule(entries)
+	}
+	if err != nil && err != errNotFound {
+		log.Println("Error importing the schedule: " + err.Error())
+		return exitFailed
+	}
+
+	session, err := files.LoadSession()
+	if err == nil {
+		err = a.store.SaveSession(session)
+	}
+	if err != nil && err != errNotFound {
+		log.Println("Error importing the session details: " + err.Error())
+		return exitFailed
+	}
+
+	log.Printf("Imported %s into %s storage: selections, %d presets, rotation history, schedule and session details", filepath.Join(appDir, accountDir(a.name)), *storageName, len(presets))
+	return exitOK
+}
+
+func vaultCommand(a *account, args []string) int {
+	if len(args) != 1 {
+		return usageError("vault needs one of set, show or delete")
+	}
+	switch args[0] {
+	case "set":
+		login, ok := credentials{Username: a.state.Username(), Password: os.Getenv(envPassword)}, true
+		if login.Password == "" {
+			login, ok = promptCredentials(login.Username)
+		} else if login.Username == "" {
+			login.Username = os.Getenv(envUsername)
+		}
+		if !ok || login.Username == "" {
+			return usageError("vault set needs a terminal to ask for the login, or -username and " + envPassword)
+		}
+		passphrase := os.Getenv(envPassphrase)
+		if passphrase == "" {
+			var err error
+			passphrase, err = readSecret("New vault passphrase: ")
+			if err != nil {
+				log.Println(err.Error())
+				return exitUsage
+			}
+			repeated, err := readSecret("Repeat the passphrase: ")
+			if err != nil || repeated != passphrase {
+				log.Println("The passphrases differ")
+				return exitUsage
+			}
+		}
+		if passphrase == "" {
+			return usageError("The vault passphrase must not be empty")
+		}
+		data, err := sealVault(login, passphrase)
+		if err == nil {
+			err = writeFileAtomic(a.vault.file(), data)
+		}
+		if err != nil {
+			log.Println("Error saving the vault: " + err.Error())
+			return exitFailed
+		}
+		log.Println("Stored the login of " + login.Username + " in the vault")
+	case "show":
+		if !a.vault.Available() {
+			log.Println("There is no vault")
+			return exitFailed
+		}
+		login, err := a.vault.Credentials()
+		if err != nil {
+			log.Println("Error opening the vault: " + err.Error())
+			return exitFailed
+		}
+		fmt.Println(login.Username)
+	case "delete":
+		err := os.Remove(filepath.Join(appDir, a.vault.file()))
+		// The backups hold the login too, under the same passphrase
+		for generation := 1; generation <= maxGenerations; generation++ {
+			os.Remove(filepath.Join(appDir, generationName(a.vault.file(), generation)))
+		}
+		if err != nil {
+			log.Println("Error deleting the vault: " + err.Error())
+			return exitFailed
+		}
+		log.Println("Deleted the vault")
+	default:
+		return usageError("vault needs one of set, show or delete")
+	}
+	return exitOK
+}
+
+func accountCommand(args []string) int {
+	if len(args) < 1 {
+		return usageError("account needs one of list or add")
+	}
+	switch args[0] {
+	case "list":
+		if len(args) != 1 {
+			return usageError("account list takes no arguments")
+		}
+		for _, a := range allAccounts() {
+			saved, err := a.store.LoadSession()
+			if err == nil && len(saved.Cookies) > 0 {
+				fmt.Println(a.name + ": saved session of " + saved.Username + " from " + saved.LastLogin.Format("2006-01-02 15:04"))
+			} else {
+				fmt.Println(a.name + ": no saved session")
+			}
+		}
+	case "add":
+		if len(args) != 2 {
+			return usageError("account add takes an account name")
+		}
+		a, err := addAccount(args[1])
+		if err == errInvalidAccountName || err == errAccountExists {
+			return usageError(err.Error())
+		}
+		if err != nil {
+			log.Println("Error adding the account: " + err.Error())
+			return exitFailed
+		}
+		log.Println("Added account " + a.name + ". Log it in with: microbadger -account " + a.name + " sync")
+	default:
+		return usageError("account needs one of list or add")
+	}
+	return exitOK
+}
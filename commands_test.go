@@ -0,0 +1,255 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"testing"
+)
+
+// setEnv sets an environment variable for one test.
+func setEnv(t *testing.T, key, value string) {
+	t.Helper()
+	saved, wasSet := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if wasSet {
+			os.Setenv(key, saved)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+// newCommandAccount returns the default account talking to a fake with the
+// profile of testdata, with user/pw in the environment for the commands to
+// log in with. The log is silenced, as runCommand writes to it.
+func newCommandAccount(t *testing.T) (*account, *fakeBGG) {
+	t.Helper()
+	page, err := ioutil.ReadFile("testdata/microbadges/profile.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeBGG(page)
+	fake.Username, fake.Password = "user", "pw"
+	a := newTestAccount(t, fake)
+	setEnv(t, envUsername, "user")
+	setEnv(t, envPassword, "pw")
+
+	savedHeadless, savedFlags := *headless, log.Flags()
+	t.Cleanup(func() {
+		*headless = savedHeadless
+		log.SetOutput(os.Stderr)
+		log.SetFlags(savedFlags)
+	})
+	log.SetOutput(ioutil.Discard)
+	return a, fake
+}
+
+// run runs a command and keeps the log quiet while it does.
+func run(args ...string) int {
+	code := runCommand(args)
+	log.SetOutput(ioutil.Discard)
+	return code
+}
+
+func TestCommandUsageErrors(t *testing.T) {
+	_, fake := newCommandAccount(t)
+	for _, args := range [][]string{
+		{"unknown"},
+		{"sync", "extra"},
+		{"list", "extra"},
+		{"assign", "1"},
+		{"assign", "0", "1001"},
+		{"assign", "one", "1001"},
+		{"clear"},
+		{"clear", "9"},
+		{"randomize", "--twice"},
+		{"randomize", "--once", "extra"},
+		{"preset"},
+		{"preset", "list", "extra"},
+		{"preset", "load"},
+		{"preset", "load", "../escape"},
+		{"preset", "rename", "only-one"},
+		{"preset", "unknown", "name"},
+		{"schedule", "extra"},
+		{"import", "extra"},
+		{"import"},
+		{"vault"},
+		{"vault", "unknown"},
+		{"account"},
+		{"account", "unknown"},
+		{"account", "add"},
+		{"account", "add", "../escape"},
+	} {
+		if code := run(args...); code != exitUsage {
+			t.Errorf("%q exited with %d, want %d", args, code, exitUsage)
+		}
+	}
+	if len(fake.Calls) != 0 {
+		t.Errorf("usage errors called BoardGameGeek: %v", fake.Calls)
+	}
+}
+
+func TestSyncCommand(t *testing.T) {
+	a, _ := newCommandAccount(t)
+	if code := run("sync"); code != exitOK {
+		t.Fatalf("sync exited with %d", code)
+	}
+	saved, err := a.store.LoadSelections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Badges) != 7 {
+		t.Errorf("sync saved %d badges, want the 7 of the profile", len(saved.Badges))
+	}
+
+	// The next run finds nothing new
+	page, _ := ioutil.ReadFile("testdata/microbadges/profile.html")
+	fake := newFakeBGG(page)
+	next := reopenAccount(t, fake)
+	if code := run("sync"); code != exitOK {
+		t.Fatalf("the second sync exited with %d", code)
+	}
+	if synced := syncNotifications(next); len(synced) != 0 {
+		t.Errorf("the second sync notified %q", synced)
+	}
+}
+
+func TestCommandLoginFailure(t *testing.T) {
+	_, fake := newCommandAccount(t)
+	setEnv(t, envPassword, "wrong")
+	for _, args := range [][]string{{"sync"}, {"list"}, {"assign", "1", "1001"}, {"randomize", "--once"}} {
+		if code := run(args...); code != exitLoginFailed {
+			t.Errorf("%q with a wrong password exited with %d, want %d", args, code, exitLoginFailed)
+		}
+	}
+	fake.Failures["FetchMicrobadges"] = errors.New("unavailable")
+	setEnv(t, envPassword, "pw")
+	if code := run("sync"); code != exitFailed {
+		t.Errorf("sync with a failing fetch exited with %d, want %d", code, exitFailed)
+	}
+}
+
+func TestListCommand(t *testing.T) {
+	newCommandAccount(t)
+	if code := run("list"); code != exitOK {
+		t.Errorf("list exited with %d", code)
+	}
+}
+
+func TestAssignAndClearCommands(t *testing.T) {
+	a, fake := newCommandAccount(t)
+	if code := run("assign", "2", "1001"); code != exitOK {
+		t.Fatalf("assign exited with %d", code)
+	}
+	if fake.Slots["2"] != "1001" {
+		t.Errorf("slot 2 shows %q, want 1001", fake.Slots["2"])
+	}
+	if code := run("clear", "2"); code != exitOK {
+		t.Fatalf("clear exited with %d", code)
+	}
+	if _, ok := fake.Slots["2"]; ok {
+		t.Errorf("slot 2 still shows %q", fake.Slots["2"])
+	}
+	history, err := a.store.LoadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := history["2"]; len(entries) != 2 || entries[0].BadgeId != "" || entries[1].BadgeId != "1001" {
+		t.Errorf("slot 2 has the history %v", entries)
+	}
+
+	fake.Failures["SetSlot"] = errors.New("rejected")
+	if code := run("assign", "2", "1001"); code != exitFailed {
+		t.Errorf("a failing assign exited with %d, want %d", code, exitFailed)
+	}
+}
+
+func TestRandomizeOnceCommand(t *testing.T) {
+	a, fake := newCommandAccount(t)
+	if code := run("sync"); code != exitOK {
+		t.Fatalf("sync exited with %d", code)
+	}
+	if err := a.submitCheckedMicroBadges(map[string][]string{"1": {"1001"}, "2": {"1003"}}); err != nil {
+		t.Fatal(err)
+	}
+	if code := run("randomize", "--once"); code != exitOK {
+		t.Fatalf("randomize --once exited with %d", code)
+	}
+	if fake.Slots["1"] != "1001" || fake.Slots["2"] != "1003" {
+		t.Errorf("the slots show %v", fake.Slots)
+	}
+	// Emptied by hand, so a failed update cannot look applied
+	fake.Slots = map[string]string{}
+	fake.Failures["SetSlot"] = errors.New("rejected")
+	if code := run("randomize", "--once"); code != exitFailed {
+		t.Errorf("a failing randomize exited with %d, want %d", code, exitFailed)
+	}
+}
+
+func TestPresetCommands(t *testing.T) {
+	a, _ := newCommandAccount(t)
+	for _, step := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"preset", "save", "weekend"}, exitOK},
+		{[]string{"preset", "list"}, exitOK},
+		{[]string{"preset", "duplicate", "weekend", "copy"}, exitOK},
+		{[]string{"preset", "duplicate", "weekend", "copy"}, exitFailed},
+		{[]string{"preset", "rename", "copy", "renamed"}, exitOK},
+		{[]string{"preset", "rename", "copy", "again"}, exitFailed},
+		{[]string{"preset", "show", "renamed"}, exitOK},
+		{[]string{"preset", "load", "renamed"}, exitOK},
+		{[]string{"preset", "delete", "renamed"}, exitOK},
+		{[]string{"preset", "delete", "renamed"}, exitFailed},
+		{[]string{"preset", "load", "renamed"}, exitFailed},
+		{[]string{"preset", "show", "renamed"}, exitFailed},
+	} {
+		if code := run(step.args...); code != step.code {
+			t.Errorf("%q exited with %d, want %d", step.args, code, step.code)
+		}
+	}
+	if presets := a.getPresets(); len(presets) != 1 || presets[0] != "weekend" {
+		t.Errorf("the presets are %v, want only weekend", presets)
+	}
+}
+
+func TestScheduleCommand(t *testing.T) {
+	a, _ := newCommandAccount(t)
+	if code := run("schedule"); code != exitOK {
+		t.Errorf("schedule without entries exited with %d", code)
+	}
+	err := a.updateSchedule(schedule{{Preset: "weekend", Weekdays: []string{"Sat", "Sun"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := run("schedule"); code != exitOK {
+		t.Errorf("schedule exited with %d", code)
+	}
+}
+
+func TestAccountCommands(t *testing.T) {
+	newCommandAccount(t)
+	if code := run("account", "add", "second"); code != exitOK {
+		t.Fatalf("account add exited with %d", code)
+	}
+	if _, ok := getAccount("second"); !ok {
+		t.Error("the account was not added")
+	}
+	if code := run("account", "add", "second"); code != exitUsage {
+		t.Errorf("adding an existing account exited with %d, want %d", code, exitUsage)
+	}
+	if code := run("account", "list"); code != exitOK {
+		t.Errorf("account list exited with %d", code)
+	}
+
+	saved := *accountName
+	defer func() { *accountName = saved }()
+	*accountName = "missing"
+	if code := run("sync"); code != exitUsage {
+		t.Errorf("sync for an unknown account exited with %d, want %d", code, exitUsage)
+	}
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh/terminal"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Environment variables read by the credential providers. envAccount is set
+// for -credential-command to the account it is run for.
+const (
+	envUsername   = "MICROBADGER_USERNAME"
+	envPassword   = "MICROBADGER_PASSWORD"
+	envPassphrase = "MICROBADGER_PASSPHRASE"
+	envAccount    = "MICROBADGER_ACCOUNT"
+)
+
+const (
+	vaultFile    = "credentials.vault"
+	vaultVersion = 1
+	// credentialCommandTimeout bounds how long -credential-command may take
+	credentialCommandTimeout = 30 * time.Second
+)
+
+var errWrongPassphrase = errors.New("The passphrase does not open the vault")
+
+// credentials are a BoardGameGeek login.
+type credentials struct {
+	Username string
+	Password string
+}
+
+// String leaves the password out, so that printing credentials cannot show
+// it.
+func (c credentials) String() string {
+	return c.Username + " (password hidden)"
+}
+
+func (c credentials) GoString() string {
+	return c.String()
+}
+
+// credentialProvider is somewhere the login can come from.
+type credentialProvider interface {
+	// Name says where the credentials come from, for messages.
+	Name() string
+	// Available reports whether the provider is set up, without running a
+	// command or asking for a passphrase.
+	Available() bool
+	Credentials() (credentials, error)
+}
+
+// staticCredentials were given once: by the deprecated -password flag, the
+// login form or the terminal prompt.
+type staticCredentials struct {
+	name  string
+	login credentials
+}
+
+func (s staticCredentials) Name() string {
+	return s.name
+}
+
+func (s staticCredentials) Available() bool {
+	return s.login.Username != "" && s.login.Password != ""
+}
+
+func (s staticCredentials) Credentials() (credentials, error) {
+	return s.login, nil
+}
+
+// envCredentials reads MICROBADGER_USERNAME and MICROBADGER_PASSWORD.
+type envCredentials struct{}
+
+func (envCredentials) Name() string {
+	return "the environment"
+}
+
+func (envCredentials) Available() bool {
+	return os.Getenv(envUsername) != "" && os.Getenv(envPassword) != ""
+}
+
+func (envCredentials) Credentials() (credentials, error) {
+	login := credentials{Username: os.Getenv(envUsername), Password: os.Getenv(envPassword)}
+	if login.Username == "" || login.Password == "" {
+		return credentials{}, errors.New(envUsername + " and " + envPassword + " must both be set")
+	}
+	rememberSecret(login.Password)
+	return login, nil
+}
+
+// commandCredentials runs -credential-command for an account, which prints
+// username=<name> and password=<password> lines like a git credential
+// helper. MICROBADGER_ACCOUNT tells it which account to print. Without a
+// username line the username of the account is used.
+type commandCredentials struct {
+	command string
+	account *account
+}
+
+func (c commandCredentials) Name() string {
+	return "-credential-command"
+}
+
+func (c commandCredentials) Available() bool {
+	return c.command != ""
+}
+
+func (c commandCredentials) Credentials() (credentials, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), credentialCommandTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", c.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", c.command)
+	}
+	cmd.Env = append(os.Environ(), envAccount+"="+c.account.name)
+	// The helper may ask for an unlock on the terminal
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return credentials{}, errors.New("the command did not finish within " + credentialCommandTimeout.String())
+	}
+	if err != nil {
+		// The output is left out, as it may hold the password
+		return credentials{}, errors.New("the command failed: " + err.Error())
+	}
+	login := credentials{Username: c.account.state.Username()}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "username=") {
+			login.Username = strings.TrimPrefix(line, "username=")
+		} else if strings.HasPrefix(line, "password=") {
+			login.Password = strings.TrimPrefix(line, "password=")
+		}
+	}
+	if login.Username == "" || login.Password == "" {
+		return credentials{}, errors.New("the command must print a password= line, and a username= line unless the username is known")
+	}
+	rememberSecret(login.Password)
+	return login, nil
+}
+
+// vaultCredentials reads the login from the vault file in an account's
+// directory, encrypted with a key derived from a passphrase. The passphrase
+// comes from MICROBADGER_PASSPHRASE or is asked for on the terminal, once
+// per run.
+type vaultCredentials struct {
+	// dir is the account's directory relative to appDir
+	dir string
+
+	mu       sync.Mutex
+	unlocked *credentials
+}
+
+func (v *vaultCredentials) Name() string {
+	return "the vault"
+}
+
+// file returns the name of the vault file relative to appDir.
+func (v *vaultCredentials) file() string {
+	return filepath.Join(v.dir, vaultFile)
+}
+
+func (v *vaultCredentials) Available() bool {
+	_, err := os.Stat(filepath.Join(appDir, v.file()))
+	return err == nil
+}
+
+func (v *vaultCredentials) Credentials() (credentials, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.unlocked != nil {
+		return *v.unlocked, nil
+	}
+	data, err := ioutil.ReadFile(filepath.Join(appDir, v.file()))
+	if err != nil {
+		return credentials{}, err
+	}
+	passphrase, err := readPassphrase("Vault passphrase: ")
+	if err != nil {
+		return credentials{}, err
+	}
+	login, err := openVault(data, passphrase)
+	if err != nil {
+		return credentials{}, err
+	}
+	rememberSecret(login.Password)
+	v.unlocked = &login
+	return login, nil
+}
+
+// vault is the format of the vault file. Data is the login encrypted with
+// AES-256-GCM under a key derived from the passphrase and Salt with scrypt.
+type vault struct {
+	Version int
+	Salt    []byte
+	Nonce   []byte
+	Data    []byte
+}
+
+func vaultCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealVault encrypts login with passphrase into the contents of a vault
+// file.
+func sealVault(login credentials, passphrase string) ([]byte, error) {
+	sealed := vault{Version: vaultVersion, Salt: make([]byte, 16)}
+	if _, err := rand.Read(sealed.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := vaultCipher(passphrase, sealed.Salt)
+	if err != nil {
+		return nil, err
+	}
+	sealed.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(sealed.Nonce); err != nil {
+		return nil, err
+	}
+	plain, err := json.Marshal(login)
+	if err != nil {
+		return nil, err
+	}
+	sealed.Data = aead.Seal(nil, sealed.Nonce, plain, nil)
+	return json.MarshalIndent(sealed, "", "  ")
+}
+
+// openVault decrypts the contents of a vault file.
+func openVault(data []byte, passphrase string) (credentials, error) {
+	var sealed vault
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return credentials{}, errors.New("The vault is damaged: " + err.Error())
+	}
+	if sealed.Version != vaultVersion {
+		return credentials{}, fmt.Errorf("The vault has the unknown version %d", sealed.Version)
+	}
+	aead, err := vaultCipher(passphrase, sealed.Salt)
+	if err != nil {
+		return credentials{}, err
+	}
+	if len(sealed.Nonce) != aead.NonceSize() {
+		return credentials{}, errors.New("The vault is damaged")
+	}
+	plain, err := aead.Open(nil, sealed.Nonce, sealed.Data, nil)
+	if err != nil {
+		return credentials{}, errWrongPassphrase
+	}
+	var login credentials
+	if err := json.Unmarshal(plain, &login); err != nil {
+		return credentials{}, errors.New("The vault is damaged: " + err.Error())
+	}
+	return login, nil
+}
+
+// readPassphrase returns MICROBADGER_PASSPHRASE, or asks for the passphrase
+// on the terminal.
+func readPassphrase(prompt string) (string, error) {
+	if passphrase := os.Getenv(envPassphrase); passphrase != "" {
+		return passphrase, nil
+	}
+	return readSecret(prompt)
+}
+
+// readSecret asks for a secret on the terminal without echoing it.
+func readSecret(prompt string) (string, error) {
+	if !terminal.IsTerminal(int(syscall.Stdin)) {
+		return "", errors.New(strings.TrimSuffix(prompt, ": ") + " needed, but there is no terminal to ask on")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := terminal.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(secret), "\r\n"), nil
+}
+
+// flagCredentials holds the login given with the deprecated -password flag
+// for the -account account, once takePasswordFlag has moved it out of the
+// flag.
+var flagCredentials credentialProvider
+
+// takePasswordFlag warns that -password is deprecated and moves the password
+// out of the flag, so that only the login source keeps it.
+func takePasswordFlag() {
+	if *password == "" {
+		return
+	}
+	log.Println("Warning: -password is deprecated, as the password shows in the process list and config files. Set " + envUsername + " and " + envPassword + ", use -credential-command, or store it with: microbadger vault set")
+	rememberSecret(*password)
+	flagCredentials = staticCredentials{name: "the -password flag", login: credentials{Username: *username, Password: *password}}
+	*password = ""
+}
+
+// credentialProviders returns the providers of the account in the order
+// they are tried. The flags and the environment only hold the login of the
+// -account account.
+func (a *account) credentialProviders() []credentialProvider {
+	providers := []credentialProvider{}
+	if a.name == *accountName {
+		if flagCredentials != nil {
+			providers = append(providers, flagCredentials)
+		}
+		providers = append(providers, envCredentials{})
+	}
+	return append(providers, commandCredentials{command: *credentialCommand, account: a}, a.vault)
+}
+
+// availableCredentials returns the first provider of the account that is set
+// up, or nil.
+func (a *account) availableCredentials() credentialProvider {
+	for _, provider := range a.credentialProviders() {
+		if provider.Available() {
+			return provider
+		}
+	}
+	return nil
+}
+
+// loginWith logs the account in with the credentials from source and keeps
+// source for logging in again.
+func (a *account) loginWith(source credentialProvider) error {
+	login, err := source.Credentials()
+	if err != nil {
+		return errors.New("Reading the credentials from " + source.Name() + " failed: " + err.Error())
+	}
+	err = a.bgg.Login(login.Username, login.Password)
+	if err != nil {
+		return err
+	}
+	a.state.setUsername(login.Username)
+	a.setLoginSource(source)
+	a.rememberLogin()
+	return nil
+}
+
+var (
+	secretsMu sync.Mutex
+	// secrets are the passwords seen in this run, hidden by redactSecrets
+	secrets []string
+)
+
+func rememberSecret(secret string) {
+	if secret == "" {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, known := range secrets {
+		if known == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+}
+
+// redactSecrets hides every known password in text, so that no error
+// message can put one into the log or the notifications.
+func redactSecrets(text string) string {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, secret := range secrets {
+		text = strings.Replace(text, secret, "********", -1)
+	}
+	return text
+}
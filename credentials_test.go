@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestVaultRoundTrip(t *testing.T) {
+	login := credentials{"user", "vault-secret"}
+	data, err := sealVault(login, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), login.Password) {
+		t.Error("the vault holds the password in plain text")
+	}
+	opened, err := openVault(data, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened != login {
+		t.Errorf("the vault opened to %#v", opened)
+	}
+	if _, err := openVault(data, "wrong"); err != errWrongPassphrase {
+		t.Errorf("a wrong passphrase gave %v, want %v", err, errWrongPassphrase)
+	}
+}
+
+func TestTamperedVault(t *testing.T) {
+	data, err := sealVault(credentials{"user", "vault-secret"}, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tamper := func(change func(sealed *vault)) []byte {
+		var sealed vault
+		if err := json.Unmarshal(data, &sealed); err != nil {
+			t.Fatal(err)
+		}
+		change(&sealed)
+		tampered, err := json.Marshal(sealed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tampered
+	}
+	for name, test := range map[string]struct {
+		data []byte
+		want string
+	}{
+		"changed ciphertext": {tamper(func(sealed *vault) { sealed.Data[0] ^= 1 }), errWrongPassphrase.Error()},
+		"changed salt":       {tamper(func(sealed *vault) { sealed.Salt[0] ^= 1 }), errWrongPassphrase.Error()},
+		"short nonce":        {tamper(func(sealed *vault) { sealed.Nonce = sealed.Nonce[1:] }), "The vault is damaged"},
+		"unknown version":    {tamper(func(sealed *vault) { sealed.Version = vaultVersion + 1 }), "The vault has the unknown version 2"},
+		"not JSON":           {data[:len(data)/2], "The vault is damaged"},
+	} {
+		if _, err := openVault(test.data, "passphrase"); err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("%s gave %v, want %q", name, err, test.want)
+		}
+	}
+}
+
+func TestVaultCredentials(t *testing.T) {
+	a := newTestAccount(t, newFakeBGG(nil))
+	if a.vault.Available() {
+		t.Fatal("a vault is available before one was saved")
+	}
+	data, err := sealVault(credentials{"user", "vault-file-secret"}, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(appDir, a.vault.file())
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	setEnv(t, envPassphrase, "wrong")
+	if _, err := a.vault.Credentials(); err != errWrongPassphrase {
+		t.Errorf("a wrong passphrase gave %v", err)
+	}
+	setEnv(t, envPassphrase, "passphrase")
+	login, err := a.vault.Credentials()
+	if err != nil || login != (credentials{"user", "vault-file-secret"}) {
+		t.Errorf("the vault gave %#v, %v", login, err)
+	}
+	if redacted := redactSecrets("vault-file-secret"); redacted != "********" {
+		t.Errorf("the password from the vault is not redacted: %q", redacted)
+	}
+}
+
+func TestCommandCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands are written for sh")
+	}
+	a := newTestAccount(t, newFakeBGG(nil))
+	a.state.setUsername("known")
+	for _, test := range []struct {
+		command string
+		want    credentials
+		err     string
+	}{
+		{"echo username=user; echo password=command-secret", credentials{"user", "command-secret"}, ""},
+		{"echo password=command-secret", credentials{"known", "command-secret"}, ""},
+		{`echo "username=$MICROBADGER_ACCOUNT"; echo password=command-secret`, credentials{defaultAccount, "command-secret"}, ""},
+		{"true", credentials{}, "the command must print a password= line"},
+		{"echo username=user", credentials{}, "the command must print a password= line"},
+		{"echo password=command-secret; exit 3", credentials{}, "the command failed: exit status 3"},
+	} {
+		login, err := commandCredentials{command: test.command, account: a}.Credentials()
+		if test.err == "" && err != nil {
+			t.Errorf("%q gave %v", test.command, err)
+		}
+		if test.err != "" && (err == nil || !strings.HasPrefix(err.Error(), test.err)) {
+			t.Errorf("%q gave %v, want %q", test.command, err, test.err)
+		}
+		if err != nil && strings.Contains(err.Error(), "command-secret") {
+			t.Errorf("%q gave an error holding the password: %v", test.command, err)
+		}
+		if login != test.want {
+			t.Errorf("%q gave %#v, want %#v", test.command, login, test.want)
+		}
+	}
+}
+
+func TestLoginWithFailingProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command is written for sh")
+	}
+	fake := newFakeBGG(nil)
+	a := newTestAccount(t, fake)
+	err := a.loginWith(commandCredentials{command: "exit 1", account: a})
+	if err == nil || !strings.HasPrefix(err.Error(), "Reading the credentials from -credential-command failed") {
+		t.Errorf("logging in with a failing command gave %v", err)
+	}
+	if len(fake.Calls) != 0 {
+		t.Errorf("BoardGameGeek was called without credentials: %v", fake.Calls)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	a := newTestAccount(t, newFakeBGG(nil))
+	rememberSecret("redacted-secret")
+	rememberSecret("")
+	if redacted := redactSecrets("no secret here"); redacted != "no secret here" {
+		t.Errorf("text without secrets became %q", redacted)
+	}
+
+	a.state.notify("Login with redacted-secret failed: redacted-secret")
+	for _, message := range a.state.notificationList() {
+		if strings.Contains(message, "redacted-secret") {
+			t.Errorf("the notification %q holds the password", message)
+		}
+		if !strings.Contains(message, "Login with ******** failed: ********") {
+			t.Errorf("the notification is %q", message)
+		}
+	}
+
+	setRetries(t, 0)
+	tr := newBGGTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection reset sending password=redacted-secret")
+	}), 0, 1)
+	req, _ := http.NewRequest("POST", "http://bgg.test/login", strings.NewReader("password=redacted-secret"))
+	if _, err := tr.RoundTrip(req); err == nil {
+		t.Fatal("the failing request succeeded")
+	}
+	if lastError := tr.snapshot().LastError; strings.Contains(lastError, "redacted-secret") || !strings.Contains(lastError, "********") {
+		t.Errorf("the transport reports %q", lastError)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// fakeBGG is an in-memory bggClient. It serves Page as the microbadge page,
+// keeps the slot assignments it was given and records every call so the
+// rotation logic can be exercised without network access.
+type fakeBGG struct {
+	mu sync.Mutex
+
+	Username string
+	Password string
+	Page     []byte
+	Slots    map[string]string
+	Calls    []string
+	// Failures makes a method fail. Keys are a method name, or a method
+	// name and slot such as "SetSlot 3" to fail a single slot.
+	Failures map[string]error
+
+	loggedIn bool
+}
+
+func newFakeBGG(page []byte) *fakeBGG {
+	return &fakeBGG{Page: page, Slots: map[string]string{}, Failures: map[string]error{}}
+}
+
+func (f *fakeBGG) failure(method, slotNumber string) error {
+	if err, ok := f.Failures[method+" "+slotNumber]; ok {
+		return err
+	}
+	return f.Failures[method]
+}
+
+func (f *fakeBGG) Login(username, password string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, "Login "+username)
+	if err := f.failure("Login", ""); err != nil {
+		return err
+	}
+	if f.Username != "" && (username != f.Username || password != f.Password) {
+		return errLoginRejected
+	}
+	f.loggedIn = true
+	return nil
+}
+
+func (f *fakeBGG) CheckSession() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, "CheckSession")
+	if err := f.failure("CheckSession", ""); err != nil {
+		return err
+	}
+	if !f.loggedIn {
+		return errSessionExpired
+	}
+	return nil
+}
+
+func (f *fakeBGG) FetchMicrobadges(username string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, "FetchMicrobadges "+username)
+	if err := f.failure("FetchMicrobadges", ""); err != nil {
+		return nil, err
+	}
+	if !f.loggedIn {
+		return nil, errSessionExpired
+	}
+	return ioutil.NopCloser(bytes.NewReader(f.Page)), nil
+}
+
+func (f *fakeBGG) SetSlot(slotNumber, badgeID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, "SetSlot "+slotNumber+" "+badgeID)
+	if err := f.failure("SetSlot", slotNumber); err != nil {
+		return err
+	}
+	if !f.loggedIn {
+		return errSessionExpired
+	}
+	f.Slots[slotNumber] = badgeID
+	return nil
+}
+
+func (f *fakeBGG) ClearSlot(slotNumber string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, "ClearSlot "+slotNumber)
+	if err := f.failure("ClearSlot", slotNumber); err != nil {
+		return err
+	}
+	if !f.loggedIn {
+		return errSessionExpired
+	}
+	delete(f.Slots, slotNumber)
+	return nil
+}
+
+// AssignedSlots returns a copy of the slot assignments, so rotations run
+// against the fake are verified.
+func (f *fakeBGG) AssignedSlots(username string) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, "AssignedSlots "+username)
+	if err := f.failure("AssignedSlots", ""); err != nil {
+		return nil, err
+	}
+	if !f.loggedIn {
+		return nil, errors.New("Not logged in")
+	}
+	slots := make(map[string]string, len(f.Slots))
+	for slotNumber, badgeID := range f.Slots {
+		slots[slotNumber] = badgeID
+	}
+	return slots, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"log"
+	"os"
+)
+
+// Exit codes returned by runHeadless and runCommand. Anything non-zero lets a
+// service manager such as systemd, or a script, decide what to do next.
+const (
+	exitOK          = 0
+	exitLoginFailed = 1
+	exitUsage       = 2
+	exitFailed      = 3
+	// exitListenFailed means the web interface could not listen on its
+	// address, usually because another microBadger is using it.
+	exitListenFailed = 4
+)
+
+// loginFromCredentials reuses the saved session of the account, or logs into
+// BoardGameGeek with the credentials of its first provider that is set up.
+// When there is none, the login of the -account account is asked for on the
+// terminal. It returns the exit code to use if it fails.
+func (a *account) loginFromCredentials() int {
+	source := a.availableCredentials()
+	if a.restoreSession() {
+		a.setLoginSource(source)
+		a.state.log("Logged in as " + a.state.Username() + " with the saved session")
+		return exitOK
+	}
+	if source == nil {
+		if a.name != *accountName {
+			a.state.log("Logging in requires a saved session, -credential-command, or a vault made with \"microbadger -account " + a.name + " vault set\"")
+			return exitUsage
+		}
+		login, ok := promptCredentials(a.state.Username())
+		if !ok {
+			a.state.log("Logging in requires " + envUsername + " and " + envPassword + ", -credential-command, a vault made with \"microbadger vault set\", or a terminal to prompt for them")
+			return exitUsage
+		}
+		source = staticCredentials{name: "the terminal", login: login}
+	}
+
+	err := a.loginWith(source)
+	if err != nil {
+		a.state.log("Login failed: " + redactSecrets(err.Error()))
+		return exitLoginFailed
+	}
+	a.state.log("Logged in as " + a.state.Username() + " with the credentials from " + source.Name())
+	return exitOK
+}
+
+// runHeadless logs the accounts into BoardGameGeek with their saved sessions,
+// credential providers or a terminal prompt, and runs their schedulers
+// without starting the web server or opening a browser. An account that
+// cannot log in is left out, unless it is the only one. Notifications are
+// written to stderr.
+func runHeadless(list []*account) int {
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0)
+	log.Println("MicroBadger version", VERSION, "running headless")
+	watchSignals()
+
+	running := []*account{}
+	code := exitOK
+	for _, a := range list {
+		if code = a.loginFromCredentials(); code != exitOK {
+			if len(list) > 1 {
+				a.state.log("Not rotating this account")
+			}
+			continue
+		}
+		running = append(running, a)
+	}
+	if len(running) == 0 {
+		return code
+	}
+
+	for _, a := range running {
+		a.loadState()
+		if a.state.badgeCount() == 0 {
+			a.state.log("No badge selections found in " + *storageName + " storage; every slot will be cleared until badges are selected")
+		}
+		accountLoops.Add(1)
+		go func(a *account) {
+			defer accountLoops.Done()
+			a.scheduler.run(appContext)
+		}(a)
+	}
+	accountLoops.Wait()
+	return shutdown(nil)
+}
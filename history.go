@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const historyFile = "history.mb"
+
+type historyEntry struct {
+	BadgeId string
+	Time    time.Time
+}
+
+// rotationHistory holds the most recent assignments of each slot, newest
+// first, keyed by slot Id.
+type rotationHistory map[string][]historyEntry
+
+// record adds an assignment to the front of the slot's history, dropping the
+// oldest entries beyond the configured history size.
+func (h rotationHistory) record(slotID, badgeID string, t time.Time) {
+	entries := append([]historyEntry{{BadgeId: badgeID, Time: t}}, h[slotID]...)
+	if *historySize > 0 && len(entries) > *historySize {
+		entries = entries[:*historySize]
+	}
+	h[slotID] = entries
+}
+
+// recentlyShown returns the badges assigned to the slot in its last cycles
+// assignments. Cleared slots are not counted as badges.
+func (h rotationHistory) recentlyShown(slotID string, cycles int) map[string]bool {
+	recent := map[string]bool{}
+	for i, entry := range h[slotID] {
+		if i >= cycles {
+			break
+		}
+		if entry.BadgeId != "" {
+			recent[entry.BadgeId] = true
+		}
+	}
+	return recent
+}
+
+// withoutBadges filters out every candidate whose Id is in excluded.
+func withoutBadges(candidates []*microBadge, excluded map[string]bool) []*microBadge {
+	result := make([]*microBadge, 0, len(candidates))
+	for _, mb := range candidates {
+		if !excluded[mb.Id] {
+			result = append(result, mb)
+		}
+	}
+	return result
+}
+
+func (a *account) loadHistory() {
+	loadedHistory, err := a.store.LoadHistory()
+	if err == errNotFound {
+		return
+	}
+	if err != nil {
+		a.state.notify("Error loading rotation history: " + err.Error())
+		return
+	}
+	a.state.setHistory(loadedHistory)
+}
+
+func (a *account) saveHistory() error {
+	return a.store.SaveHistory(a.state.historySnapshot())
+}
+
+func historyHandler(w http.ResponseWriter, r *http.Request) {
+	historyPage := `
+<html>
+<head>
+<title>MicroBadger rotation history</title>
+</head>
+<body>
+<a href="/">Back</a>
+{{range $slot := .Slots}}
+<h3>Slot {{$slot}}</h3>
+<table>
+<tr><th>Time</th><th>Badge</th></tr>
+{{range $entry := index $.History $slot}}
+<tr>
+<td>{{$entry.Time.Format "2006-01-02 15:04:05"}}</td>
+<td>{{if $entry.BadgeId}}{{with index $.Badges $entry.BadgeId}}<img src="{{.ImgURL}}" /> {{.Name}}{{else}}{{$entry.BadgeId}}{{end}}{{else}}(cleared){{end}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`
+	tmpl, err := template.New("").Parse(historyPage)
+	if err != nil {
+		fmt.Fprintf(w, "error: "+err.Error())
+		return
+	}
+	a := requestAccount(r)
+	history := a.state.historySnapshot()
+	slots := make([]string, 0, len(history))
+	for slotID := range history {
+		slots = append(slots, slotID)
+	}
+	sort.Strings(slots)
+	err = tmpl.Execute(w, struct {
+		Slots   []string
+		History rotationHistory
+		Badges  map[string]*microBadge
+	}{slots, history, a.state.badgesSnapshot()})
+	if err != nil {
+		fmt.Fprintf(w, "error: "+err.Error())
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownTimeout bounds how long shutting down waits for web requests, and
+// the requests to BoardGameGeek they make, to finish.
+const shutdownTimeout = 30 * time.Second
+
+// appContext is cancelled when microBadger starts shutting down, by a signal
+// or the Quit button.
+var appContext, requestShutdown = context.WithCancel(context.Background())
+
+// watchSignals shuts microBadger down on SIGINT or SIGTERM. A second signal
+// exits at once without waiting for anything.
+func watchSignals() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		received := <-signals
+		log.Println("Received " + received.String() + ", shutting down. Interrupt again to quit at once")
+		requestShutdown()
+		<-signals
+		os.Exit(exitFailed)
+	}()
+}
+
+// shutdown stops the web server, if there is one, waiting for the requests
+// it is still handling, and then saves the state of every account. The
+// schedulers must have stopped already. It returns the exit code.
+func shutdown(server *http.Server) int {
+	code := exitOK
+	if server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Println("Error stopping the web server: " + err.Error())
+			code = exitFailed
+		}
+	}
+	for _, a := range allAccounts() {
+		if err := a.flushState(); err != nil {
+			log.Println("Error saving the state of account " + a.name + ": " + err.Error())
+			code = exitFailed
+		}
+	}
+	log.Println("microBadger stopped")
+	return code
+}
+
+// flushState saves the rotation history and the selections, so that nothing
+// changed since they were last saved is lost.
+func (a *account) flushState() error {
+	err := a.saveHistory()
+	// Nothing was loaded, so there is nothing to save over the stored ones
+	if a.state.badgeCount() > 0 {
+		if selectionsErr := a.submitCheckedMicroBadges(a.state.selectedSlots()); err == nil {
+			err = selectionsErr
+		}
+	}
+	return err
+}
+
+// exit closes the storage of every account, so that bolt releases its
+// locks, and ends the process with code.
+func exit(code int) {
+	if err := closeAccounts(); err != nil {
+		log.Println("Error closing the storage: " + err.Error())
+		if code == exitOK {
+			code = exitFailed
+		}
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newAppContext gives one test an appContext of its own to shut down.
+func newAppContext(t *testing.T) {
+	savedContext, savedShutdown := appContext, requestShutdown
+	appContext, requestShutdown = context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		requestShutdown()
+		appContext, requestShutdown = savedContext, savedShutdown
+	})
+}
+
+// failingHistory is a storage that cannot save the history.
+type failingHistory struct {
+	storage
+}
+
+func (failingHistory) SaveHistory(history rotationHistory) error {
+	return errors.New("disk full")
+}
+
+func TestQuitShutsDown(t *testing.T) {
+	newAppContext(t)
+	a, _ := newRotationAccount(t)
+	a.randomizeBadges()
+	// Lose what was saved so far, as if it had not been written yet
+	if err := a.store.SaveHistory(rotationHistory{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.store.SaveSelections(&mbFile{}); err != nil {
+		t.Fatal(err)
+	}
+
+	stopped := make(chan bool)
+	go func() {
+		a.scheduler.run(appContext)
+		close(stopped)
+	}()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(quitHandler)}
+	go server.Serve(listener)
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/quit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the scheduler kept running after quitting")
+	}
+
+	if code := shutdown(server); code != exitOK {
+		t.Errorf("shutdown returned %d, want %d", code, exitOK)
+	}
+	if _, err := http.Get("http://" + listener.Addr().String() + "/quit"); err == nil {
+		t.Error("the web server still answers after shutting down")
+	}
+	history, err := a.store.LoadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 {
+		t.Errorf("the history of %d slots was saved, want 3", len(history))
+	}
+	saved, err := a.store.LoadSelections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Badges) != 6 {
+		t.Errorf("%d badges were saved, want 6", len(saved.Badges))
+	}
+}
+
+func TestShutdownReportsFailedSave(t *testing.T) {
+	a, _ := newRotationAccount(t)
+	a.randomizeBadges()
+	store := a.store
+	a.store = failingHistory{store}
+	if err := store.SaveSelections(&mbFile{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := shutdown(nil); code != exitFailed {
+		t.Errorf("shutdown returned %d, want %d", code, exitFailed)
+	}
+	// The selections are saved all the same
+	saved, err := store.LoadSelections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Badges) != 6 {
+		t.Errorf("%d badges were saved, want 6", len(saved.Badges))
+	}
+}
+
+func TestShutdownWithoutBadges(t *testing.T) {
+	a := newTestAccount(t, newFakeBGG(nil))
+	if code := shutdown(nil); code != exitOK {
+		t.Errorf("shutdown returned %d, want %d", code, exitOK)
+	}
+	// Nothing was loaded, so no empty selections are saved
+	if _, err := a.store.LoadSelections(); err != errNotFound {
+		t.Errorf("loading the selections gave %v, want %v", err, errNotFound)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// mbFileVersion is the schema version written to .mb files. Files without a
+// version are the bare badge maps written before versioning and count as
+// version 0.
+const mbFileVersion = 1
+
+// backupDir, inside appDir and every account directory, holds the original
+// of every file that was upgraded and the previous generations of every
+// saved file.
+const backupDir = "backups"
+
+// mbFile is the stored format of the selections and the presets.
+type mbFile struct {
+	Version   int
+	Created   time.Time
+	Username  string
+	SlotCount int
+	Badges    map[string]*microBadge
+	// Strategies holds the strategies chosen for single slots, by slot id.
+	// Slots without one use the -strategy flag.
+	Strategies map[string]string `json:",omitempty"`
+
+	// upgradedFrom is the version the file had on disk when it was older
+	// than mbFileVersion.
+	upgradedFrom int
+}
+
+// mbFileMigrations upgrades a file from version i to i+1. Add a step here
+// and bump mbFileVersion whenever the format changes.
+var mbFileMigrations = []func(f *mbFile){
+	// 0 -> 1: the bare map gains a header. Legacy files always held five
+	// slots, but take the longest selection in case one was hand-edited.
+	func(f *mbFile) {
+		f.SlotCount = defaultSlotCount
+		for _, mb := range f.Badges {
+			if len(mb.Selected) > f.SlotCount {
+				f.SlotCount = len(mb.Selected)
+			}
+		}
+	},
+}
+
+func (a *account) newMBFile(badges map[string]*microBadge) *mbFile {
+	return &mbFile{
+		Version:    mbFileVersion,
+		Created:    time.Now(),
+		Username:   a.state.Username(),
+		SlotCount:  a.state.SlotCount(),
+		Badges:     badges,
+		Strategies: a.state.slotStrategies(),
+	}
+}
+
+// readMBFile reads a .mb file in appDir and upgrades it to mbFileVersion.
+// When the file is corrupt the newest previous generation that reads is
+// used instead, and notify is told.
+func readMBFile(file string, notify func(message string)) (*mbFile, error) {
+	var f *mbFile
+	_, err := readGenerations(file, func(data []byte) error {
+		var decodeErr error
+		f, decodeErr = decodeMBFile(data)
+		return decodeErr
+	}, notify)
+	if os.IsNotExist(err) {
+		return nil, errNotFound
+	}
+	if _, ok := err.(*os.PathError); ok {
+		return nil, errors.New("Error reading file: " + err.Error())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error in file format of %s: %s", file, err.Error())
+	}
+	return f, nil
+}
+
+func decodeMBFile(data []byte) (*mbFile, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, jsonError(data, err)
+	}
+
+	f := &mbFile{}
+	if _, versioned := fields["Version"]; versioned {
+		err = json.Unmarshal(data, f)
+	} else {
+		err = json.Unmarshal(data, &f.Badges)
+	}
+	if err != nil {
+		return nil, jsonError(data, err)
+	}
+	if f.Version > mbFileVersion {
+		return nil, fmt.Errorf("version %d was written by a newer microBadger, this one reads up to version %d", f.Version, mbFileVersion)
+	}
+	if f.Version < 0 {
+		return nil, fmt.Errorf("unknown version %d", f.Version)
+	}
+	if f.Badges == nil {
+		f.Badges = map[string]*microBadge{}
+	}
+	for id, mb := range f.Badges {
+		if mb == nil {
+			return nil, fmt.Errorf("badge %s is empty", id)
+		}
+	}
+
+	f.upgradedFrom = f.Version
+	for f.Version < mbFileVersion {
+		mbFileMigrations[f.Version](f)
+		f.Version++
+	}
+	return f, nil
+}
+
+// jsonError adds the line and column of syntax and type errors, which
+// encoding/json only reports as a byte offset.
+func jsonError(data []byte, err error) error {
+	var offset int64
+	switch jsonErr := err.(type) {
+	case *json.SyntaxError:
+		offset = jsonErr.Offset
+	case *json.UnmarshalTypeError:
+		offset = jsonErr.Offset
+	default:
+		return err
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndex(before, []byte("\n"))
+	return fmt.Errorf("line %d, column %d: %s", line, column, err.Error())
+}
+
+// upgradeMBFile copies the original of an upgraded file into backupDir and
+// rewrites it in the current format.
+func upgradeMBFile(file string, f *mbFile, notify func(message string)) error {
+	original, err := ioutil.ReadFile(filepath.Join(appDir, file))
+	if err != nil {
+		return err
+	}
+	backupPath := filepath.Join(filepath.Dir(file), backupDir)
+	err = os.MkdirAll(filepath.Join(appDir, backupPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	backupName := fmt.Sprintf("%s.v%d-%s", filepath.Base(file), f.upgradedFrom, time.Now().Format("20060102-150405"))
+	err = ioutil.WriteFile(filepath.Join(appDir, backupPath, backupName), original, 0600)
+	if err != nil {
+		return err
+	}
+	err = writeMapToFile(file, f)
+	if err != nil {
+		return err
+	}
+	notify(fmt.Sprintf("Upgraded %s to file format version %d. The original was saved as %s", file, mbFileVersion, filepath.Join(backupPath, backupName)))
+	return nil
+}
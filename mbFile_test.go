@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestUpgradeLegacyMBFile(t *testing.T) {
+	a := newTestAccount(t, newFakeBGG(nil))
+	legacy := `{"1001":{"Id":"1001","Name":"A","Category":"C","Selected":[false,false,false,false,false,false,true]}}`
+	if err := ioutil.WriteFile(filepath.Join(appDir, selectedFile), []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := a.store.LoadSelections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Version != mbFileVersion || f.SlotCount != 7 || !f.Badges["1001"].IsSelected(6) {
+		t.Errorf("upgraded to version %d with %d slots and badges %v", f.Version, f.SlotCount, f.Badges)
+	}
+
+	backups, err := filepath.Glob(filepath.Join(appDir, backupDir, selectedFile+".v0-*"))
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("found the backups %v, want one of the original", backups)
+	}
+	original, err := ioutil.ReadFile(backups[0])
+	if err != nil || string(original) != legacy {
+		t.Errorf("the backup holds %q, want the original", original)
+	}
+	info, err := os.Stat(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("the backup has mode %v, want 0600", info.Mode().Perm())
+	}
+
+	// The rewritten file loads without another upgrade
+	if _, err := a.store.LoadSelections(); err != nil {
+		t.Fatal(err)
+	}
+	if backups, _ = filepath.Glob(filepath.Join(appDir, backupDir, selectedFile+".v0-*")); len(backups) != 1 {
+		t.Errorf("found the backups %v after loading again", backups)
+	}
+}
+
+func TestReadMBFileErrors(t *testing.T) {
+	appDir = t.TempDir()
+	for _, test := range []struct {
+		content string
+		want    string
+	}{
+		{`{"Version":99}`, "newer microBadger"},
+		{"{\n\"1\": {\"Id\": 5}\n}", "line 2"},
+		{`{"1": null}`, "badge 1 is empty"},
+	} {
+		if err := ioutil.WriteFile(filepath.Join(appDir, "preset-x.mb"), []byte(test.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := readMBFile("preset-x.mb", func(string) {})
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("reading %q: got %v, want an error mentioning %q", test.content, err, test.want)
+		}
+	}
+	if _, err := readMBFile("missing.mb", func(string) {}); err != errNotFound {
+		t.Errorf("reading a missing file: got %v, want errNotFound", err)
+	}
+}
+
+func TestNewerFileIsLeftUntouched(t *testing.T) {
+	a := newTestAccount(t, newFakeBGG(nil))
+	newer := `{"Version":9,"Badges":{"1001":{"Id":"1001","Selected":[true]}}}`
+	file := filepath.Join(appDir, selectedFile)
+	if err := ioutil.WriteFile(file, []byte(newer), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.loadSelections(); err == nil || !strings.Contains(err.Error(), "newer microBadger") {
+		t.Errorf("loading got %v, want the newer version reported", err)
+	}
+	if content, err := ioutil.ReadFile(file); err != nil || string(content) != newer {
+		t.Errorf("selected.mb holds %q after loading, want it unchanged", content)
+	}
+	if backups, _ := filepath.Glob(filepath.Join(appDir, backupDir, selectedFile+"*")); len(backups) != 0 {
+		t.Errorf("loading wrote the backups %v", backups)
+	}
+}
@@ -5,14 +5,12 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	website "github.com/allentechnology/website"
 	"github.com/blang/semver"
 	"github.com/vharitonsky/iniflags"
 	"html/template"
-	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
-	"net/url"
 	"os"
 	"os/exec"
 	"os/user"
@@ -20,6 +18,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -40,28 +39,13 @@ var funcMap = template.FuncMap{
 		}
 		return sum
 	},
-	"showPresets": func() []string {
-		presetFiles := make([]string, 0)
-		files, err := ioutil.ReadDir(appDir)
-		if err != nil {
-			return presetFiles
-		}
-
-		for _, v := range files {
-			if strings.Contains(v.Name(), "preset-") {
-				currentName := v.Name()
-				currentName = strings.Replace(currentName, "preset-", "", -1)
-				currentName = strings.Replace(currentName, ".mb", "", -1)
-				presetFiles = append(presetFiles, currentName)
-			}
-		}
-		return presetFiles
-	},
 	"checkUpdate": func() bool {
-		return needToUpdate
+		_, needsUpdate := release.get()
+		return needsUpdate
 	},
 	"getLatestVersion": func() string {
-		return latestVersion
+		latest, _ := release.get()
+		return latest
 	},
 	"getVersion": func() string {
 		return VERSION
@@ -87,65 +71,94 @@ var funcMap = template.FuncMap{
 		}
 		return result
 	},
-	"getPresets": func() []string {
-		fileList, err := ioutil.ReadDir(appDir)
-		presetList = make([]string, 0)
-		if err != nil {
-			return presetList
-		}
-		for _, file := range fileList {
-			if strings.HasPrefix(file.Name(), "preset-") {
-				presetList = append(presetList, file.Name())
-			}
-		}
-		for i, v := range presetList {
-			presetList[i] = strings.TrimPrefix(strings.TrimSuffix(v, ".mb"), "preset-")
-		}
-		return presetList
+	"getStrategies": func() []string {
+		return strategyNames
+	},
+	"getAccounts": accountNames,
+	"bggStatus": func() bggStats {
+		return bggTraffic.snapshot()
 	},
 }
 
-var (
-	slotMap          = map[string]*slot{}
-	categoryMap      = map[string]mbSlice{}
-	microBadgeMap    = map[string]*microBadge{}
-	tmpMicroBadgeMap = map[string]*microBadge{}
-	presetList       = make([]string, 0)
-	presetChan       = make(chan bool)
-	client           *http.Client
-)
-
-type notification []string
+// accountFuncs returns the template functions showing the account a page is
+// for.
+func accountFuncs(a *account) template.FuncMap {
+	return template.FuncMap{
+		"showPresets": a.getPresets,
+		"slotStrategy": func(slotID string) string {
+			return a.state.slotStrategy(slotID)
+		},
+		"getPresets": a.getPresets,
+		"getSlots": func() []string {
+			return a.state.slotIDs()
+		},
+		"sessionStatus": func() sessionStatus {
+			return a.state.sessionSnapshot()
+		},
+		"currentAccount": func() string {
+			return a.name
+		},
+	}
+}
 
-func (n *notification) notify(message string) {
-	if len(notifications) > 50 {
-		notifications = notifications[:len(notifications)-1]
+// getPresets returns the names of the saved presets.
+func (a *account) getPresets() []string {
+	presetList, err := a.store.Presets()
+	if err != nil {
+		a.state.notify("Error listing presets: " + err.Error())
+		return []string{}
 	}
-	currentTime := time.Now().Format("2006-01-02 15:04:05 ")
-	*n = append(notification{currentTime + ": " + message}, *n...)
+	return presetList
 }
 
-var (
-	notifications = make(notification, 0)
-)
+// releaseInfo is the latest released version, shared by all accounts.
+type releaseInfo struct {
+	mu            sync.RWMutex
+	latestVersion string
+	needToUpdate  bool
+}
 
-var (
-	loginReady        = make(chan bool)
-	usingSelectedFile = make(chan bool, 1)
-)
+var release releaseInfo
+
+func (r *releaseInfo) set(latest string) {
+	needsUpdate := compareVersions(VERSION, latest)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latestVersion = latest
+	r.needToUpdate = needsUpdate
+}
+
+func (r *releaseInfo) get() (latest string, needsUpdate bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.latestVersion, r.needToUpdate
+}
 
 var (
-	username = flag.String("username", "", "The boardgamegeek.com username used to log into the site")
-	password = flag.String("password", "", "The boardgamegeek.com password associated with the given username")
-	version  = flag.Bool("version", false, "Print the executable version to the screen")
-	interval = flag.Int("interval", 1, "The interval between randomizations in minutes")
+	username          = flag.String("username", "", "The boardgamegeek.com username used to log into the site")
+	password          = flag.String("password", "", "Deprecated: set MICROBADGER_USERNAME and MICROBADGER_PASSWORD, use -credential-command or \"microbadger vault set\" instead")
+	credentialCommand = flag.String("credential-command", "", "A command printing username=<name> and password=<password> lines, run whenever microBadger needs to log in")
+	version           = flag.Bool("version", false, "Print the executable version to the screen")
+	interval          = flag.Int("interval", 1, "The interval between randomizations in minutes")
+	jitter            = flag.Int("jitter", 0, "Vary each interval randomly by up to this many percent, so randomizations do not happen at exactly regular times")
+	strategy          = flag.String("strategy", defaultStrategy, "The default badge selection strategy for each slot: random, round-robin, least-recent or weighted")
+	seed              = flag.Int64("seed", 0, "Seed for the random selection strategies. 0 seeds from the current time")
+	noRepeat          = flag.Int("no-repeat", 1, "Do not show a badge in a slot again within this many cycles of the slot's history. 0 allows repeats")
+	historySize       = flag.Int("history-size", 100, "The number of past assignments kept per slot in the rotation history")
+	bggBaseURL        = flag.String("bgg-base-url", "https://boardgamegeek.com", "The base URL of the BoardGameGeek site, e.g. a local mock server for offline runs")
+	bggRate           = flag.Float64("bgg-rate", 1, "The number of requests a second sent to BoardGameGeek, for all accounts together. 0 does not limit them")
+	bggBurst          = flag.Int("bgg-burst", 2, "The number of requests sent to BoardGameGeek at once before -bgg-rate applies")
+	bggTimeout        = flag.Int("bgg-timeout", 30, "The number of seconds each request to BoardGameGeek may take")
+	bggRetries        = flag.Int("bgg-retries", 3, "How often a BoardGameGeek request failing with a network error, 429 or 5xx is retried")
+	slotCount         = flag.Int("slots", defaultSlotCount, "The number of microbadge slots to rotate")
+	storageName       = flag.String("storage", "files", "Where selections, presets and history are kept: files in the microBadger directory, or bolt for a single database file")
+	headless          = flag.Bool("headless", false, "Run without the web interface, logging in with the saved session or the credentials and logging to stderr")
+	accountName       = flag.String("account", defaultAccount, "The account that -username, -password, the environment and commands are for")
 )
 
 var (
 	appDir        = ""
 	runtimeOS     = ""
-	latestVersion = ""
-	needToUpdate  = false
 	listenAddress = "localhost:8080"
 )
 
@@ -155,8 +168,7 @@ func init() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	latestVersion = checkForUpdates()
-	needToUpdate = compareVersions(VERSION, latestVersion)
+	release.set(checkForUpdates())
 	runtimeOS = runtime.GOOS
 	switch runtime.GOOS {
 	case "linux":
@@ -170,20 +182,56 @@ func init() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	go cyclePresets([]string{})
 }
 
 func main() {
 	iniflags.Parse()
+	takePasswordFlag()
 	if *version {
 		fmt.Println(VERSION)
 		os.Exit(0)
 	}
-	loadMicroBadgesFromFile("selected.mb")
-	categoryMap = getCategories()
-	go webServer()
-
-	var err error
+	if !validStrategy(*strategy) {
+		log.Fatal("unknown selection strategy: " + *strategy)
+	}
+	if *jitter < 0 || *jitter >= 100 {
+		log.Fatal("the jitter must be from 0 to 99 percent")
+	}
+	if *slotCount < 1 {
+		log.Fatal("the number of slots must be at least 1")
+	}
+	if *bggRate < 0 || *bggBurst < 1 || *bggTimeout < 1 || *bggRetries < 0 {
+		log.Fatal("-bgg-rate and -bgg-retries must not be negative, and -bgg-burst and -bgg-timeout must be at least 1")
+	}
+	bggTraffic = newBGGTransport(http.DefaultTransport, *bggRate, *bggBurst)
+	if !validAccountName(*accountName) {
+		log.Fatal(errInvalidAccountName)
+	}
+	err := openAccounts()
+	if err != nil {
+		log.Fatal("Error opening storage: " + err.Error())
+	}
+	if flag.NArg() > 0 {
+		exit(runCommand(flag.Args()))
+	}
+	if *headless {
+		exit(runHeadless(allAccounts()))
+	}
+	// Listen before loading any account so a second microBadger on a busy
+	// port stops here without resubmitting or saving anything
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		log.Println("Cannot start the web interface on " + listenAddress + ": " + err.Error())
+		log.Println("Is another microBadger already running? Quit it or open http://" + listenAddress + " instead")
+		exit(exitListenFailed)
+	}
+	for _, a := range allAccounts() {
+		a.loadState()
+		a.startWeb()
+	}
+	watchSignals()
+	server := &http.Server{}
+	go webServer(server, listener)
 
 	localURL := "http://" + listenAddress
 	switch runtimeOS {
@@ -202,71 +250,39 @@ func main() {
 	}
 	fmt.Println("MicroBadger version ", VERSION)
 	fmt.Println("To use microBadger, navigate to", localURL, "in any web browser.")
-	<-loginReady
-
-	//	client = logIntoBGG()
-	//	loggedIn := true
-	for {
-		// if !loggedIn {
-		// 	client = logIntoBGG()
-		// }
-		latestVersion = checkForUpdates()
-		needToUpdate = compareVersions(VERSION, latestVersion)
-		notifications.notify("Attempting to randomize badges: ")
-		err := getMicroBadges(client)
-		if err != nil {
-			notifications.notify("Failed")
-			notifications.notify(err.Error())
-			time.Sleep(10 * time.Second)
-			continue
-		}
-		randomizeBadges()
-		time.Sleep(time.Duration(*interval) * time.Minute)
-	}
+	<-appContext.Done()
+	accountLoops.Wait()
+	exit(shutdown(server))
 }
 
-func loadMicroBadgesFromFile(file string) {
-	for {
-		fileName := filepath.Join(appDir, file)
-		if _, err := os.Stat(fileName); err == nil {
-			usingSelectedFile <- true
-			inFile, err := os.Open(fileName)
-			if err != nil {
-				notifications.notify("Error opening file: " + err.Error())
-				<-usingSelectedFile
-				break
-			}
-			defer inFile.Close()
-			selectedBytes, err := ioutil.ReadAll(inFile)
-			if err != nil {
-				notifications.notify("Error reading file: " + err.Error())
-				<-usingSelectedFile
-				break
-			}
-			tmpMicroBadgeMap = map[string]*microBadge{}
-			microBadgeMap = map[string]*microBadge{}
-			err = json.Unmarshal(selectedBytes, &tmpMicroBadgeMap)
-			if err != nil {
-				notifications.notify("Error in file format: " + err.Error())
-				<-usingSelectedFile
-				break
-			}
-			<-usingSelectedFile
-			microBadgeMap = tmpMicroBadgeMap
-		}
-		break
-	}
-	availableSlots := make(map[string][]string)
-	for _, v := range microBadgeMap {
+// loadSelections restores the saved badge selections and rebuilds the slots
+// from them.
+func (a *account) loadSelections() error {
+	return a.applyLoadedMicroBadges(a.store.LoadSelections())
+}
+
+// loadPreset makes a saved preset the current selections.
+func (a *account) loadPreset(name string) error {
+	return a.applyLoadedMicroBadges(a.store.LoadPreset(name))
+}
 
-		for i, sel := range v.Selected {
-			index := fmt.Sprintf("%d", i+1)
-			if sel {
-				availableSlots[index] = append(availableSlots[index], v.Id)
+// applyLoadedMicroBadges makes loaded the current selections and saves them.
+// A file that could not be loaded, such as one from a newer microBadger, is
+// reported and left as it is.
+func (a *account) applyLoadedMicroBadges(loaded *mbFile, err error) error {
+	if err != nil && err != errNotFound {
+		a.state.notify(err.Error())
+		return err
+	}
+	if err == nil {
+		a.state.replaceBadges(loaded.Badges)
+		for slotID, name := range loaded.Strategies {
+			if validStrategy(name) {
+				a.state.setSlotStrategy(slotID, name)
 			}
 		}
 	}
-	submitCheckedMicroBadges(availableSlots)
+	return a.submitCheckedMicroBadges(a.state.selectedSlots())
 }
 
 func compareVersions(curVer, newVer string) bool {
@@ -303,59 +319,27 @@ func getVersionFromURL(url string) string {
 	return stringSlice[len(stringSlice)-1]
 }
 
-func randomizeBadges() {
-	badgeList := getRandomBadges()
-	updateSuccess := make([]bool, len(badgeList))
-	var err error
-	for i, v := range badgeList {
-		err = assignSlot(v.Id, fmt.Sprintf("%d", i+1), client)
-		if err != nil {
-			//			fmt.Println("Error assigning slot ", i+1, ": ", err.Error())
-			updateSuccess[i] = false
-			//	loggedIn = false
-		} else {
-			updateSuccess[i] = true
-		}
-
-	}
-	updateMessage := "Slots "
-	slotUpdated := false
-	for i, v := range updateSuccess {
-		if v {
-			updateMessage += fmt.Sprintf("%d ", i+1)
-			slotUpdated = true
-		}
-	}
-	if slotUpdated {
-		updateMessage += "updated successfully"
-	} else {
-		updateMessage += "not updated"
-	}
-	notifications.notify(updateMessage)
+// randomizeBadges picks a badge for every slot and rotates them as a unit.
+func (a *account) randomizeBadges() []slotResult {
+	a.rotateMu.Lock()
+	defer a.rotateMu.Unlock()
+	results := a.planRotation(a.state.pickBadges(a.strategies))
+	a.rotate(results)
+	a.recordRotation(results, time.Now())
+	a.state.notify(rotationMessage(results))
+	return results
 }
 
-func cyclePresets(selectedPresets []string) {
-	if len(selectedPresets) < 1 {
-		<-presetChan
-
-	} else {
-		for {
-			for _, v := range selectedPresets {
-				select {
-				case <-presetChan:
-					return
-				default:
-					notifications.notify("loading " + v + " preset")
-					loadMicroBadgesFromFile("preset-" + v + ".mb")
-
-				}
-				time.Sleep(time.Duration(*interval)*time.Minute + time.Second)
-			}
-		}
-	}
+// startPresetCycle makes the scheduler cycle through selectedPresets, starting
+// with the first one now. An empty list stops cycling.
+func (a *account) startPresetCycle(selectedPresets []string) {
+	a.state.setActivePresets(selectedPresets)
+	a.scheduler.restartCycle()
 }
 
-func webServer() {
+// webServer serves the web interface and the API on listener until shutdown.
+// If serving fails, microBadger shuts down.
+func webServer(server *http.Server, listener net.Listener) {
 	//Web server here
 	http.HandleFunc("/", rootHandler)
 	http.HandleFunc("/slot/", slotHandler)
@@ -369,20 +353,32 @@ func webServer() {
 	http.HandleFunc("/header", headerHandler)
 	http.HandleFunc("/savePreset", savePresetHandler)
 	http.HandleFunc("/loadPreset", loadPresetHandler)
+	http.HandleFunc("/preset", presetHandler)
+	http.HandleFunc("/presetRename", presetRenameHandler)
+	http.HandleFunc("/presetDuplicate", presetDuplicateHandler)
+	http.HandleFunc("/presetDelete", presetDeleteHandler)
 	http.HandleFunc("/notify", notifyHandler)
-	serverErr := http.ListenAndServe(listenAddress, nil)
-
-	if serverErr != nil {
-		os.Exit(0)
+	http.HandleFunc("/history", historyHandler)
+	http.HandleFunc("/schedule", scheduleHandler)
+	http.HandleFunc("/scheduleAdd", scheduleAddHandler)
+	http.HandleFunc("/scheduleDelete", scheduleDeleteHandler)
+	http.HandleFunc("/account", accountHandler)
+	http.HandleFunc("/accountAdd", accountAddHandler)
+	http.HandleFunc(apiPrefix, apiHandler)
+	serverErr := server.Serve(listener)
+
+	if serverErr != http.ErrServerClosed {
+		log.Println("The web interface stopped: " + serverErr.Error())
+		requestShutdown()
 	}
-
 }
 
 func notifyHandler(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
 	currentNotification := r.Form["notification"]
+	a := requestAccount(r)
 	for _, v := range currentNotification {
-		notifications.notify(v)
+		a.state.notify(v)
 	}
 	http.Redirect(w, r, "http://"+listenAddress, http.StatusSeeOther)
 }
@@ -393,8 +389,13 @@ func savePresetHandler(w http.ResponseWriter, r *http.Request) {
 	var err error = nil
 	if len(presetName) > 0 {
 		if len(presetName[0]) > 0 {
-			fileName := "preset-" + presetName[0] + ".mb"
-			writeMapToFile(fileName, microBadgeMap)
+			a := requestAccount(r)
+			saveErr := a.savePreset(presetName[0])
+			if saveErr != nil {
+				a.state.notify(saveErr.Error())
+				http.Error(w, presetErrorMessage(saveErr), presetErrorStatus(saveErr))
+				return
+			}
 		} else {
 			err = errors.New("Preset name not provided")
 		}
@@ -412,6 +413,8 @@ func loadPresetHandler(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
 	presetNames := r.Form["preset"]
 	requestedPresets := make([]string, 0)
+	a := requestAccount(r)
+	presetList := a.getPresets()
 	for _, v := range presetNames {
 		currentPreset := v
 		for _, validPreset := range presetList {
@@ -421,8 +424,7 @@ func loadPresetHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if len(requestedPresets) > 0 {
-		presetChan <- true
-		go cyclePresets(requestedPresets)
+		a.startPresetCycle(requestedPresets)
 		http.Redirect(w, r, "http://"+listenAddress, http.StatusSeeOther)
 		return
 	}
@@ -448,7 +450,7 @@ func notificationHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		fmt.Fprintf(w, "error: "+err.Error())
 	}
-	err = tmpl.Execute(w, notifications)
+	err = tmpl.Execute(w, requestAccount(r).state.notificationList())
 	if err != nil {
 		fmt.Fprintf(w, "error: "+err.Error())
 	}
@@ -456,13 +458,11 @@ func notificationHandler(w http.ResponseWriter, r *http.Request) {
 }
 func quitHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, "<html><body><h3>Exiting microBadger</h3><p>Thank you for using this application</p></body></html>")
-	go func() {
-		time.Sleep(5 * time.Second)
-		os.Exit(0)
-	}()
+	// The server finishes this response before it shuts down
+	requestShutdown()
 }
 func randomizeHandler(w http.ResponseWriter, r *http.Request) {
-	randomizeBadges()
+	requestAccount(r).randomizeBadges()
 }
 
 func testHandler(w http.ResponseWriter, r *http.Request) {
@@ -475,7 +475,7 @@ func testHandler(w http.ResponseWriter, r *http.Request) {
 </body>
 </html>
 `
-	tmpl, err := template.New("").Funcs(funcMap).Parse(testString)
+	tmpl, err := template.New("").Funcs(funcMap).Funcs(accountFuncs(requestAccount(r))).Parse(testString)
 
 	if err != nil {
 		fmt.Fprintf(w, "error: "+err.Error())
@@ -500,10 +500,11 @@ func setIntervalHandler(w http.ResponseWriter, r *http.Request) {
 
 	if len(intervalSlice) > 0 {
 		formInterval, err := strconv.Atoi(intervalSlice[0])
-		if err != nil {
+		if err != nil || formInterval < 1 {
+			http.Error(w, "The interval must be a whole number of minutes greater than zero", http.StatusBadRequest)
 			return
 		}
-		*interval = formInterval
+		requestAccount(r).scheduler.setInterval(formInterval)
 	}
 	return
 }
@@ -516,11 +517,12 @@ func rootHandler(w http.ResponseWriter, r *http.Request) {
 	// }
 	webpage, err := Asset("webpage.html")
 	if err == nil {
-		tmpl, err := template.New("").Funcs(funcMap).Parse(string(webpage))
+		a := requestAccount(r)
+		tmpl, err := template.New("").Funcs(funcMap).Funcs(accountFuncs(a)).Parse(string(webpage))
 		if err != nil {
 			fmt.Fprintf(w, "error: "+err.Error())
 		}
-		err = tmpl.Execute(w, categoryMap)
+		err = tmpl.Execute(w, a.state.categoriesSnapshot())
 		if err != nil {
 			fmt.Fprintf(w, "error: "+err.Error())
 		}
@@ -531,87 +533,65 @@ func rootHandler(w http.ResponseWriter, r *http.Request) {
 
 func slotSubmitHandler(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
+	a := requestAccount(r)
 	formSlots := make(map[string][]string)
-	formSlots["1"] = r.Form["slot1"]
-	formSlots["2"] = r.Form["slot2"]
-	formSlots["3"] = r.Form["slot3"]
-	formSlots["4"] = r.Form["slot4"]
-	formSlots["5"] = r.Form["slot5"]
-	submitCheckedMicroBadges(formSlots)
-}
-
-func submitCheckedMicroBadges(formSlots map[string][]string) {
-	mbSelectedMap := make(map[string][]bool)
-	for i := 1; i < 6; i++ {
-		slotID := fmt.Sprintf("%d", i)
-		if s, ok := slotMap[slotID]; ok {
-			s.AvailableBadges = map[string]*microBadge{}
-			for _, v := range formSlots[slotID] {
-				if mb, ok := microBadgeMap[v]; ok {
-					if mbSelected, ok := mbSelectedMap[v]; ok {
-						mbSelected[i-1] = true
-					} else {
-						mbSelectedMap[v] = make([]bool, 5)
-						mbSelectedMap[v][i-1] = true
-					}
-					//					mb.Selected[i] = true
-					s.AvailableBadges[v] = mb
-				}
-			}
-		} else {
-			newMap := make(map[string]*microBadge)
-			slotMap[slotID] = &slot{Id: slotID, AvailableBadges: newMap}
-			for _, v := range formSlots[slotID] {
-				if mb, ok := microBadgeMap[v]; ok {
-					if mbSelected, ok := mbSelectedMap[v]; ok {
-						mbSelected[i-1] = true
-					} else {
-						mbSelectedMap[v] = make([]bool, 5)
-						mbSelectedMap[v][i-1] = true
-					}
-					// mb.Selected[i] = true
-					slotMap[slotID].AvailableBadges[v] = mb
-				}
-			}
-
+	for _, slotID := range a.state.slotIDs() {
+		formSlots[slotID] = r.Form["slot"+slotID]
+	}
+	for key, values := range r.Form {
+		if !strings.HasPrefix(key, "weight") || len(values) < 1 {
+			continue
+		}
+		// Weight fields are named weight<slot>-<badge id>
+		fieldParts := strings.SplitN(strings.TrimPrefix(key, "weight"), "-", 2)
+		if len(fieldParts) != 2 {
+			continue
+		}
+		slotNumber, err := strconv.Atoi(fieldParts[0])
+		if err != nil || slotNumber < 1 || slotNumber > a.state.SlotCount() {
+			continue
 		}
+		weight, err := strconv.ParseFloat(values[0], 64)
+		if err != nil || !validWeight(weight) {
+			a.state.notify("Ignoring invalid weight for badge " + fieldParts[1] + " in slot " + fieldParts[0])
+			continue
+		}
+		a.state.setWeight(slotNumber-1, fieldParts[1], weight)
 	}
-	selectedMicroBadges := make(map[string]*microBadge)
-
-	for key, mb := range microBadgeMap {
-		if mbSelected, ok := mbSelectedMap[key]; ok {
-			for i, sel := range mbSelected {
-				mb.Selected[i] = sel
-			}
-			selectedMicroBadges[mb.Id] = mb
-		} else {
-			for i, _ := range mb.Selected {
-				mb.Selected[i] = false
-			}
+	for slotID := range formSlots {
+		if formStrategy := r.Form.Get("strategy" + slotID); validStrategy(formStrategy) {
+			a.state.setSlotStrategy(slotID, formStrategy)
 		}
 	}
-
-	usingSelectedFile <- true
-	fileName := "selected.mb"
-	writeMapToFile(fileName, selectedMicroBadges)
-	<-usingSelectedFile
+	err := a.submitCheckedMicroBadges(formSlots)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
 }
 
-func writeMapToFile(fileName string, givenMap interface{}) {
+// submitCheckedMicroBadges applies the selections and saves them. The
+// selections stay applied when saving fails.
+func (a *account) submitCheckedMicroBadges(formSlots map[string][]string) error {
+	knownMicroBadges := a.state.submitSelections(formSlots)
+
+	err := a.store.SaveSelections(a.newMBFile(knownMicroBadges))
+	if err != nil {
+		a.state.notify(err.Error())
+	}
+	return err
+}
 
+// writeMapToFile saves givenMap as JSON to fileName in appDir, atomically.
+func writeMapToFile(fileName string, givenMap interface{}) error {
 	toWritetoFile, err := json.Marshal(givenMap)
 	if err != nil {
-		notifications.notify("Error opening file: " + err.Error())
-		return
+		return errors.New("Error encoding " + fileName + ": " + err.Error())
 	}
-	outFile, err := os.Create(filepath.Join(appDir, fileName))
+	err = writeFileAtomic(fileName, toWritetoFile)
 	if err != nil {
-		notifications.notify("Error saving selections to file: " + err.Error())
-		return
+		return errors.New("Error saving " + fileName + ": " + err.Error())
 	}
-	defer outFile.Close()
-	outFile.Write(toWritetoFile)
-
+	return nil
 }
 
 func loginHandler(w http.ResponseWriter, r *http.Request) {
@@ -622,114 +602,43 @@ func loginHandler(w http.ResponseWriter, r *http.Request) {
 		//Print error to notification area
 		return
 	}
-	*username = usernameSlice[0]
-	*password = passwordSlice[0]
-
-	var err error
-	client, err = website.Login("https://boardgamegeek.com/login", *username, *password, 30*time.Second)
+	rememberSecret(passwordSlice[0])
+	a := requestAccount(r)
+	err := a.loginWith(staticCredentials{name: "the login form", login: credentials{Username: usernameSlice[0], Password: passwordSlice[0]}})
 
 	if err != nil {
-		notifications.notify(err.Error())
-		if err.Error() == "Login failed" {
+		a.state.notify(err.Error())
+		if errors.Is(err, errLoginRejected) {
 			return
 		}
 	} else {
-		notifications.notify("Login successful. Reload page")
-		loginReady <- true
+		a.state.notify("Login successful. Reload page")
+		select {
+		case a.loginReady <- true:
+		default:
+		}
 	}
 
 }
 
 func slotHandler(w http.ResponseWriter, r *http.Request) {
 	slotNumber := r.URL.Path[6:]
-	if _, ok := slotMap[string(slotNumber)]; ok {
-		if mb, ok := microBadgeMap[slotMap[string(slotNumber)].AssignedBadge]; ok {
-			fmt.Fprintf(w, "<html><head><meta http-equiv='refresh' content='0; url=http:%s' /></head></html>", mb.ImgURL)
-		}
+	if mb, ok := requestAccount(r).state.assignedBadge(slotNumber); ok {
+		fmt.Fprintf(w, "<html><head><meta http-equiv='refresh' content='0; url=http:%s' /></head></html>", mb.ImgURL)
 	}
 
 }
 
-func logIntoBGG() (client *http.Client) {
-	for {
-		var err error
-		client, err = website.Login("https://boardgamegeek.com/login", *username, *password, 30*time.Second)
-
-		if err != nil {
-			fmt.Println("BGG currently unavailable")
-			//			log.Fatal(err)
-		} else {
-			return
-		}
-		time.Sleep(10 * time.Second)
-	}
-}
-
-func getRandomBadges() []microBadge {
-
-	badgeList := []microBadge{}
-	for i := 1; i < 6; i++ {
-		slotID := fmt.Sprintf("%d", i)
-		if currentSlot, ok := slotMap[slotID]; ok {
-			if len(currentSlot.AvailableBadges) > 0 {
-				for _, mb := range currentSlot.AvailableBadges {
-					mbAlreadyUsed := false
-					for _, v := range badgeList {
-						if v.Id == mb.Id {
-							mbAlreadyUsed = true
-							break
-						}
-					}
-					if mbAlreadyUsed {
-						continue
-					}
-					badgeList = append(badgeList, *mb)
-					break
-				}
-			} else {
-				badgeList = append(badgeList, microBadge{Id: ""})
-			}
-		} /*else {
-			badgeList = append(badgeList, microBadge{})
-		}*/
-	}
-	return badgeList
-}
-
-func assignSlot(id, slotNumber string, client *http.Client) error {
+func (a *account) assignSlot(id, slotNumber string) error {
 	var err error
-	var resp *http.Response
 	if id == "" {
-		resp, err = client.PostForm("https://boardgamegeek.com/geekmicrobadge.php", url.Values{
-			"slot":   {slotNumber},
-			"ajax":   {"1"},
-			"action": {"clearslot"},
-		})
-
+		err = a.bgg.ClearSlot(slotNumber)
 	} else {
-		resp, err = client.PostForm("https://boardgamegeek.com/geekmicrobadge.php", url.Values{
-			"badgeid": {id},
-			"slot":    {slotNumber},
-			"ajax":    {"1"},
-			"action":  {"setslot"},
-		})
-	}
-	if err != nil {
-		return err
+		err = a.bgg.SetSlot(slotNumber, id)
 	}
-	data, err := ioutil.ReadAll(resp.Body)
-	defer resp.Body.Close()
 	if err != nil {
 		return err
 	}
-	//Response if not logged in is 85 bytes long
-	if len(data) < 86 {
-		return errors.New("Invalid username or password. Restart microBadger and attempt to log in again.")
-	}
-	if givenSlot, ok := slotMap[slotNumber]; ok {
-		givenSlot.AssignedBadge = id
-	} else {
-		slotMap[slotNumber] = &slot{Id: slotNumber, AssignedBadge: id}
-	}
+	a.state.setAssignedBadge(slotNumber, id)
 	return nil
 }
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// newRotationAccount returns an account logged in to a fake with six badges,
+// of which slot 1 may show 1 or 2, slot 2 may show 3 or 4 and slot 3 may
+// show 5 or 6.
+func newRotationAccount(t *testing.T) (*account, *fakeBGG) {
+	t.Helper()
+	fake := newFakeBGG(nil)
+	a := newTestAccount(t, fake)
+	setUpRotation(t, a)
+	return a, fake
+}
+
+// setUpRotation logs a in and gives it the slots and badges of
+// newRotationAccount.
+func setUpRotation(t *testing.T, a *account) {
+	t.Helper()
+	if err := a.loginWith(staticCredentials{name: "the test", login: credentials{"user", "pw"}}); err != nil {
+		t.Fatal(err)
+	}
+	a.state.setSlotCount(3)
+	badges := map[string]*microBadge{}
+	for _, id := range []string{"1", "2", "3", "4", "5", "6"} {
+		badges[id] = &microBadge{Id: id, Name: "Badge " + id}
+	}
+	a.state.replaceBadges(badges)
+	err := a.submitCheckedMicroBadges(map[string][]string{"1": {"1", "2"}, "2": {"3", "4"}, "3": {"5", "6"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubmitCheckedMicroBadges(t *testing.T) {
+	a, _ := newRotationAccount(t)
+	err := a.submitCheckedMicroBadges(map[string][]string{"1": {"1", "2"}, "3": {"2", "unknown"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	selected := a.state.selectedSlots()
+	for _, ids := range selected {
+		sort.Strings(ids)
+	}
+	want := map[string][]string{"1": {"1", "2"}, "2": {}, "3": {"2"}}
+	for slotID, ids := range want {
+		if len(ids) != len(selected[slotID]) || len(ids) > 0 && !reflect.DeepEqual(ids, selected[slotID]) {
+			t.Errorf("slot %s picks from %v, want %v", slotID, selected[slotID], ids)
+		}
+	}
+
+	saved, err := a.store.LoadSelections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Username != "user" || saved.SlotCount != 3 {
+		t.Errorf("saved for %q with %d slots, want user with 3", saved.Username, saved.SlotCount)
+	}
+	if got := saved.Badges["2"].Selected; !reflect.DeepEqual(got, []bool{true, false, true}) {
+		t.Errorf("badge 2 is saved as selected for %v", got)
+	}
+	if mb, ok := saved.Badges["3"]; !ok || mb.IsSelected(0) || mb.IsSelected(1) || mb.IsSelected(2) {
+		t.Errorf("badge 3 is saved as %+v, want it kept without selections", mb)
+	}
+}
+
+func TestRandomizeBadges(t *testing.T) {
+	a, fake := newRotationAccount(t)
+	results := a.randomizeBadges()
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	allowed := map[string][]string{"1": {"1", "2"}, "2": {"3", "4"}, "3": {"5", "6"}}
+	for _, r := range results {
+		if !r.Updated || r.Outcome != slotUpdated {
+			t.Errorf("slot %s: %+v", r.Slot, r)
+		}
+		if id := fake.Slots[r.Slot]; id != r.BadgeId || id != allowed[r.Slot][0] && id != allowed[r.Slot][1] {
+			t.Errorf("slot %s shows %q, picked %q from %v", r.Slot, id, r.BadgeId, allowed[r.Slot])
+		}
+		if history := a.state.historySnapshot()[r.Slot]; len(history) != 1 || history[0].BadgeId != r.BadgeId {
+			t.Errorf("slot %s has history %v", r.Slot, history)
+		}
+	}
+	if _, err := a.store.LoadHistory(); err != nil {
+		t.Errorf("the history was not saved: %v", err)
+	}
+}
+
+func TestRandomizeBadgesSlotFailure(t *testing.T) {
+	a, fake := newRotationAccount(t)
+	before := a.randomizeBadges()
+	fake.Failures["SetSlot 2"] = errors.New("rejected")
+
+	results := a.randomizeBadges()
+	if results[1].Updated || results[1].Outcome != slotFailed || results[1].Error != "rejected" {
+		t.Errorf("slot 2: %+v", results[1])
+	}
+	for i, r := range results {
+		if fake.Slots[r.Slot] != before[i].BadgeId {
+			t.Errorf("slot %s shows %q, want its previous badge %q", r.Slot, fake.Slots[r.Slot], before[i].BadgeId)
+		}
+	}
+	for slotID, history := range a.state.historySnapshot() {
+		if len(history) != 1 {
+			t.Errorf("slot %s has history %v after a failed rotation", slotID, history)
+		}
+	}
+}
+
+func TestLoginFailure(t *testing.T) {
+	fake := newFakeBGG(nil)
+	fake.Username, fake.Password = "user", "pw"
+	a := newTestAccount(t, fake)
+	err := a.loginWith(staticCredentials{name: "the test", login: credentials{"user", "wrong"}})
+	if err == nil {
+		t.Fatal("logged in with a wrong password")
+	}
+	if got := a.state.sessionSnapshot().State; got != sessionFailed {
+		t.Errorf("the session is %s, want %s", got, sessionFailed)
+	}
+	if a.state.Username() != "" {
+		t.Errorf("the username was set to %q", a.state.Username())
+	}
+	if _, err := a.store.LoadSession(); err != errNotFound {
+		t.Errorf("a failed login was saved: %v", err)
+	}
+	if err := a.getMicroBadges(); err == nil {
+		t.Error("synced without a login")
+	}
+
+	err = a.loginWith(staticCredentials{name: "the test", login: credentials{"user", "pw"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := a.state.sessionSnapshot().State; got != sessionLoggedIn {
+		t.Errorf("the session is %s, want %s", got, sessionLoggedIn)
+	}
+}
+
+func TestSyncFromFake(t *testing.T) {
+	page, err := ioutil.ReadFile("testdata/microbadges/profile.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeBGG(page)
+	a := newTestAccount(t, fake)
+	if err := a.loginWith(staticCredentials{name: "the test", login: credentials{"user", "pw"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.getMicroBadges(); err != nil {
+		t.Fatal(err)
+	}
+	if a.state.badgeCount() == 0 {
+		t.Error("no badges were synced")
+	}
+	fake.Failures["FetchMicrobadges"] = errors.New("unavailable")
+	if err := a.getMicroBadges(); err == nil {
+		t.Error("a failed fetch was not reported")
+	}
+}
+
+func TestSyncLogsInAgain(t *testing.T) {
+	page, err := ioutil.ReadFile("testdata/microbadges/profile.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeBGG(page)
+	a := newTestAccount(t, fake)
+	if err := a.loginWith(staticCredentials{name: "the test", login: credentials{"user", "pw"}}); err != nil {
+		t.Fatal(err)
+	}
+	// BoardGameGeek ends the session
+	fake.mu.Lock()
+	fake.loggedIn = false
+	fake.mu.Unlock()
+
+	if err := a.getMicroBadges(); err != nil {
+		t.Fatalf("syncing with an expired session gave %v", err)
+	}
+	logins := 0
+	for _, call := range fake.Calls {
+		if call == "Login user" {
+			logins++
+		}
+	}
+	if logins != 2 {
+		t.Errorf("logged in %d times, want once more after the session expired", logins)
+	}
+	if a.state.badgeCount() == 0 {
+		t.Error("no badges were synced")
+	}
+}
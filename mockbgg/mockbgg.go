@@ -0,0 +1,172 @@
+// Command mockbgg is a stand-in for boardgamegeek.com that serves a recorded
+// microbadge page, accepts slot updates and shows them on the profile page,
+// so microBadger can be run end to end without network access:
+//
+//	mockbgg -listen localhost:8081 &
+//	microbadger -headless -bgg-base-url http://localhost:8081 -username mockuser -password mock
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	listenAddress = flag.String("listen", "localhost:8081", "The address the mock server listens on")
+	pageFile      = flag.String("page", "", "A saved microbadge page to serve instead of the built-in recording")
+	mockUsername  = flag.String("username", "", "Only accept logins for this username. Empty accepts any")
+	mockPassword  = flag.String("password", "", "Only accept logins with this password when -username is set")
+)
+
+const sessionCookie = "SessionID"
+
+var (
+	page     = recordedPage
+	slotsMu  sync.Mutex
+	slots    = map[string]string{}
+	sessions = map[string]string{}
+)
+
+func main() {
+	flag.Parse()
+	if *pageFile != "" {
+		pageBytes, err := ioutil.ReadFile(*pageFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		page = string(pageBytes)
+	}
+
+	http.HandleFunc("/login", loginHandler)
+	http.HandleFunc("/user/", microbadgesHandler)
+	http.HandleFunc("/geekmicrobadge.php", microbadgeHandler)
+	http.HandleFunc("/slots", slotsHandler)
+	http.HandleFunc("/api/users/current", currentUserHandler)
+	log.Println("Mock BoardGameGeek listening on http://" + *listenAddress)
+	log.Fatal(http.ListenAndServe(*listenAddress, nil))
+}
+
+func loginHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	username := r.Form.Get("username")
+	password := r.Form.Get("password")
+	if username == "" || (*mockUsername != "" && (username != *mockUsername || password != *mockPassword)) {
+		log.Println("Rejected login for " + username)
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+	slotsMu.Lock()
+	sessionID := fmt.Sprintf("mock-session-%d", len(sessions)+1)
+	sessions[sessionID] = username
+	slotsMu.Unlock()
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: sessionID, Path: "/"})
+	http.SetCookie(w, &http.Cookie{Name: "bggusername", Value: username, Path: "/"})
+	log.Println("Logged in " + username)
+	fmt.Fprint(w, "<html><body>Logged in</body></html>")
+}
+
+func loggedIn(r *http.Request) bool {
+	return sessionUser(r) != ""
+}
+
+// sessionUser returns the user the request is logged in as, or "".
+func sessionUser(r *http.Request) string {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return ""
+	}
+	slotsMu.Lock()
+	defer slotsMu.Unlock()
+	return sessions[cookie.Value]
+}
+
+// currentUserHandler tells who is logged in, or answers 401.
+func currentUserHandler(w http.ResponseWriter, r *http.Request) {
+	username := sessionUser(r)
+	if username == "" {
+		http.Error(w, `{"error":"Not logged in"}`, http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"username": username})
+}
+
+// mockSlotCount is how many slots the profile page shows, at least.
+const mockSlotCount = 5
+
+func microbadgesHandler(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/microbadges") {
+		fmt.Fprint(w, page)
+		return
+	}
+	if strings.Count(r.URL.Path, "/") != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	// The profile page, with the badge shown in each slot
+	slotsMu.Lock()
+	defer slotsMu.Unlock()
+	count := mockSlotCount
+	for slot := range slots {
+		if n, err := strconv.Atoi(slot); err == nil && n > count {
+			count = n
+		}
+	}
+	fmt.Fprint(w, `<html><body><div class="profile_microbadges">`)
+	for n := 1; n <= count; n++ {
+		if badgeID := slots[strconv.Itoa(n)]; badgeID != "" {
+			fmt.Fprintf(w, `<span class="mb_slot"><a href="/microbadge/%s"><img class="mb" src="//cf.geekdo-static.com/mbs/mb_%s_0.gif" /></a></span>`, badgeID, badgeID)
+		} else {
+			fmt.Fprint(w, `<span class="mb_slot"></span>`)
+		}
+	}
+	fmt.Fprint(w, `</div></body></html>`)
+}
+
+func microbadgeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "POST required", http.StatusMethodNotAllowed)
+		return
+	}
+	if !loggedIn(r) {
+		// The real site answers unauthenticated slot updates with an error
+		// asking to log in, which microBadger treats as an expired login.
+		fmt.Fprint(w, `{"error":"You must login to use this feature."}`)
+		return
+	}
+	r.ParseForm()
+	slot := r.Form.Get("slot")
+	switch r.Form.Get("action") {
+	case "setslot":
+		badgeID := r.Form.Get("badgeid")
+		slotsMu.Lock()
+		slots[slot] = badgeID
+		slotsMu.Unlock()
+		log.Println("Slot " + slot + " set to " + badgeID)
+	case "clearslot":
+		slotsMu.Lock()
+		delete(slots, slot)
+		slotsMu.Unlock()
+		log.Println("Slot " + slot + " cleared")
+	default:
+		http.Error(w, "Unknown action", http.StatusBadRequest)
+		return
+	}
+	fmt.Fprint(w, `{"success":true}`)
+}
+
+// slotsHandler reports the current slot assignments as JSON so end to end
+// runs can check what microBadger did.
+func slotsHandler(w http.ResponseWriter, r *http.Request) {
+	slotsMu.Lock()
+	defer slotsMu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(slots)
+}
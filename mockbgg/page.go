@@ -0,0 +1,44 @@
+package main
+
+// recordedPage is a trimmed copy of a BoardGameGeek microbadge page with the
+// markup the microBadger scraper relies on.
+const recordedPage = `<!DOCTYPE html>
+<html>
+<head>
+<title>Microbadges for mockuser | BoardGameGeek</title>
+</head>
+<body>
+<div class="profile_title">Microbadges for mockuser</div>
+<div class="profile_block"><table class="profile_table" width="100%">
+<tr>
+<td class="profile_category" valign="top">
+<b>Board Games</b>
+</td>
+<td>
+<a href="/microbadge/1001" title="Catan Lover"><img class="mb" data-frz-src="//cf.geekdo-static.com/mbs/mb_1001_0.gif" onmouseover="return overlib('I love Catan', WRAP );" /><img class="tilebadge" data-frz-src="//cf.geekdo-static.com/mbs/mb_1001_tile.jpg" /></a>
+<a href="/microbadge/1002" title="Carcassonne"><img class="mb" data-frz-src="//cf.geekdo-static.com/mbs/mb_1002_0.gif" onmouseover="return overlib('Carcassonne fan', WRAP );" /><img class="tilebadge" data-frz-src="//cf.geekdo-static.com/mbs/mb_1002_tile.jpg" /></a>
+<a href="/microbadge/1003" title="Agricola"><img class="mb" data-frz-src="//cf.geekdo-static.com/mbs/mb_1003_0.gif" onmouseover="return overlib('I\'d rather be playing Agricola', WRAP );" /><img class="tilebadge" data-frz-src="//cf.geekdo-static.com/mbs/mb_1003_tile.jpg" /></a>
+</td>
+</tr>
+<tr>
+<td class="profile_category" valign="top">
+<b>Geek Status</b>
+</td>
+<td>
+<a href="/microbadge/2001" title="Gold Supporter"><img class="mb" data-frz-src="//cf.geekdo-static.com/mbs/mb_2001_0.gif" onmouseover="return overlib('Gold Supporter', WRAP );" /><img class="tilebadge" data-frz-src="//cf.geekdo-static.com/mbs/mb_2001_tile.jpg" /></a>
+<a href="/microbadge/2002" title="10 Year Geek"><img class="mb" data-frz-src="//cf.geekdo-static.com/mbs/mb_2002_0.gif" onmouseover="return overlib('10 Year Geek', WRAP );" /><img class="tilebadge" data-frz-src="//cf.geekdo-static.com/mbs/mb_2002_tile.jpg" /></a>
+</td>
+</tr>
+<tr>
+<td class="profile_category" valign="top">
+<b>Location</b>
+</td>
+<td>
+<a href="/microbadge/3001" title="Ohio"><img class="mb" data-frz-src="//cf.geekdo-static.com/mbs/mb_3001_0.gif" onmouseover="return overlib('Gamer from Ohio', WRAP );" /><img class="tilebadge" data-frz-src="//cf.geekdo-static.com/mbs/mb_3001_tile.jpg" /></a>
+<a href="/microbadge/3002" title="Midwest"><img class="mb" data-frz-src="//cf.geekdo-static.com/mbs/mb_3002_0.gif" onmouseover="return overlib('Midwest Gamer', WRAP );" /><img class="tilebadge" data-frz-src="//cf.geekdo-static.com/mbs/mb_3002_tile.jpg" /></a>
+</td>
+</tr>
+</table></div>
+</body>
+</html>
+`
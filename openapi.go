@@ -0,0 +1,367 @@
+package main
+
+// openAPISpec is served at /api/v1/openapi.json.
+const openAPISpec = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "microBadger API",
+    "version": "1",
+    "description": "Read and change the badges microBadger rotates through each microbadge slot. Errors are returned as {\"Error\": \"message\"}. Every path except /accounts works on the default account, or on the account named by the account query parameter; an unknown account returns 404."
+  },
+  "servers": [{"url": "/api/v1"}],
+  "paths": {
+    "/badges": {
+      "get": {
+        "summary": "List every known microbadge",
+        "responses": {
+          "200": {"description": "Badges sorted by Id", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Badge"}}}}}
+        }
+      }
+    },
+    "/categories": {
+      "get": {
+        "summary": "List the badge Ids in each category",
+        "responses": {
+          "200": {"description": "Badge Ids keyed by category", "content": {"application/json": {"schema": {"type": "object", "additionalProperties": {"type": "array", "items": {"type": "string"}}}}}}
+        }
+      }
+    },
+    "/slots": {
+      "get": {
+        "summary": "List the selection of every slot",
+        "responses": {
+          "200": {"description": "Slots 1 to the configured slot count", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Slot"}}}}}
+        }
+      }
+    },
+    "/slots/{slot}": {
+      "parameters": [{"name": "slot", "in": "path", "required": true, "description": "1 to the configured slot count", "schema": {"type": "string", "pattern": "^[1-9][0-9]*$"}}],
+      "get": {
+        "summary": "Read one slot",
+        "responses": {
+          "200": {"description": "The slot", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Slot"}}}},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "put": {
+        "summary": "Replace the badges a slot picks from",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/SlotUpdate"}}}},
+        "responses": {
+          "200": {"description": "The updated slot", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Slot"}}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/presets": {
+      "get": {
+        "summary": "List saved presets",
+        "responses": {
+          "200": {"description": "Preset names", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Preset"}}}}}
+        }
+      }
+    },
+    "/presets/{name}": {
+      "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string", "pattern": "^[A-Za-z0-9 _-]+$"}}],
+      "get": {
+        "summary": "Show which badges a preset selects for each slot",
+        "responses": {
+          "200": {"description": "The preset", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Preset"}}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "put": {
+        "summary": "Save the current selection as the preset",
+        "responses": {
+          "200": {"description": "The preset was overwritten", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Preset"}}}},
+          "201": {"description": "The preset was created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Preset"}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "delete": {
+        "summary": "Delete the preset",
+        "responses": {
+          "204": {"description": "Deleted"},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/presets/{name}/diff": {
+      "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string", "pattern": "^[A-Za-z0-9 _-]+$"}}],
+      "get": {
+        "summary": "Compare each slot of the preset with the current selection",
+        "responses": {
+          "200": {"description": "One entry per slot", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/PresetSlotDiff"}}}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/presets/{name}/rename": {
+      "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string", "pattern": "^[A-Za-z0-9 _-]+$"}}],
+      "post": {
+        "summary": "Rename the preset",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/PresetName"}}}},
+        "responses": {
+          "200": {"description": "The renamed preset", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Preset"}}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "409": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/presets/{name}/duplicate": {
+      "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string", "pattern": "^[A-Za-z0-9 _-]+$"}}],
+      "post": {
+        "summary": "Save a copy of the preset under a new name",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/PresetName"}}}},
+        "responses": {
+          "201": {"description": "The copy", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Preset"}}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "409": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/interval": {
+      "get": {
+        "summary": "Read the randomization interval",
+        "responses": {
+          "200": {"description": "The interval", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Interval"}}}}
+        }
+      },
+      "put": {
+        "summary": "Change the randomization interval",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Interval"}}}},
+        "responses": {
+          "200": {"description": "The new interval", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Interval"}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/scheduler": {
+      "get": {
+        "summary": "Read the interval and the presets being cycled through",
+        "responses": {
+          "200": {"description": "The scheduler state", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Scheduler"}}}}
+        }
+      },
+      "put": {
+        "summary": "Set the interval and the presets to cycle through. An empty list stops cycling",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Scheduler"}}}},
+        "responses": {
+          "200": {"description": "The new scheduler state", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Scheduler"}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/schedule": {
+      "get": {
+        "summary": "Read the calendar schedule, the preset active now and the next changes",
+        "responses": {
+          "200": {"description": "The schedule", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Schedule"}}}}
+        }
+      },
+      "put": {
+        "summary": "Replace the schedule entries. Only Entries is read",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Schedule"}}}},
+        "responses": {
+          "200": {"description": "The new schedule", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Schedule"}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/accounts": {
+      "get": {
+        "summary": "List the accounts and their BoardGameGeek logins",
+        "responses": {
+          "200": {"description": "The default account first, then the others by name", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Account"}}}}}
+        }
+      },
+      "post": {
+        "summary": "Add an account, which starts logged out unless a credential provider has its login",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Account"}}}},
+        "responses": {
+          "201": {"description": "The new account", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Account"}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/session": {
+      "get": {
+        "summary": "Read the state of the BoardGameGeek login",
+        "responses": {
+          "200": {"description": "The login state", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Session"}}}}
+        }
+      }
+    },
+    "/traffic": {
+      "get": {
+        "summary": "Read the counts of requests to BoardGameGeek, their retries and the state of the circuit breaker",
+        "responses": {
+          "200": {"description": "Counts since microBadger started, for all accounts together", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Traffic"}}}}
+        }
+      }
+    },
+    "/randomize": {
+      "post": {
+        "summary": "Randomize every slot now",
+        "description": "The slots are rotated as a unit. A failed slot update is tried again, and if it still fails the slots already updated are set back to their previous badges.",
+        "responses": {
+          "200": {"description": "Every slot was updated", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/SlotResult"}}}}},
+          "502": {"description": "BoardGameGeek rejected at least one slot update, so the rotation was rolled back", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/SlotResult"}}}}}
+        }
+      }
+    }
+  },
+  "components": {
+    "responses": {
+      "Error": {"description": "The request failed", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+    },
+    "schemas": {
+      "Error": {"type": "object", "properties": {"Error": {"type": "string"}}},
+      "Badge": {
+        "type": "object",
+        "properties": {
+          "Id": {"type": "string"},
+          "Name": {"type": "string"},
+          "Description": {"type": "string"},
+          "ImgURL": {"type": "string"},
+          "TileImgURL": {"type": "string"},
+          "PageURL": {"type": "string"},
+          "Category": {"type": "string"},
+          "Selected": {"type": "array", "items": {"type": "boolean"}, "description": "Whether the badge is selected for each slot"},
+          "Weights": {"type": "array", "items": {"type": "number"}, "description": "The badge's weight in each slot. Missing entries weigh 1"},
+          "Removed": {"type": "boolean", "description": "The badge is no longer on the profile"}
+        }
+      },
+      "Slot": {
+        "type": "object",
+        "properties": {
+          "Id": {"type": "string"},
+          "Strategy": {"type": "string", "enum": ["random", "round-robin", "least-recent", "weighted"]},
+          "AssignedBadge": {"type": "string"},
+          "Badges": {"type": "array", "items": {"type": "string"}},
+          "Weights": {"type": "object", "additionalProperties": {"type": "number"}}
+        }
+      },
+      "SlotUpdate": {
+        "type": "object",
+        "required": ["Badges"],
+        "properties": {
+          "Badges": {"type": "array", "items": {"type": "string"}},
+          "Weights": {"type": "object", "additionalProperties": {"type": "number", "minimum": 0}},
+          "Strategy": {"type": "string", "enum": ["random", "round-robin", "least-recent", "weighted"]}
+        }
+      },
+      "Preset": {
+        "type": "object",
+        "properties": {
+          "Name": {"type": "string"},
+          "Slots": {"type": "object", "additionalProperties": {"type": "array", "items": {"type": "string"}}}
+        }
+      },
+      "PresetName": {"type": "object", "required": ["Name"], "properties": {"Name": {"type": "string", "pattern": "^[A-Za-z0-9 _-]+$"}}},
+      "PresetSlotDiff": {
+        "type": "object",
+        "properties": {
+          "Slot": {"type": "string"},
+          "Badges": {"type": "array", "items": {"type": "string"}, "description": "The badges the preset selects for the slot"},
+          "Added": {"type": "array", "items": {"type": "string"}, "description": "Selected by the preset but not now"},
+          "Removed": {"type": "array", "items": {"type": "string"}, "description": "Selected now but not by the preset"}
+        }
+      },
+      "Interval": {"type": "object", "properties": {"Minutes": {"type": "integer", "minimum": 1}}},
+      "Scheduler": {
+        "type": "object",
+        "properties": {
+          "IntervalMinutes": {"type": "integer", "minimum": 1},
+          "Presets": {"type": "array", "items": {"type": "string"}},
+          "NextRandomization": {"type": "string", "format": "date-time", "readOnly": true, "description": "When the next randomization is due. Missing before the first one"}
+        }
+      },
+      "ScheduleEntry": {
+        "type": "object",
+        "description": "Every condition that is set must hold. An entry without conditions is always active",
+        "required": ["Preset"],
+        "properties": {
+          "Name": {"type": "string"},
+          "Preset": {"type": "string"},
+          "Priority": {"type": "integer", "description": "The highest priority wins when entries overlap, then the earliest entry"},
+          "From": {"type": "string", "pattern": "^[0-9]{2}-[0-9]{2}$", "description": "First month-day of a yearly date range, e.g. 12-20"},
+          "Until": {"type": "string", "pattern": "^[0-9]{2}-[0-9]{2}$", "description": "Last month-day of the range, included. May be before From to wrap over the new year"},
+          "Weekdays": {"type": "array", "items": {"type": "string"}, "description": "Weekday names such as Sat or Saturday"},
+          "Start": {"type": "string", "pattern": "^[0-9]{1,2}:[0-9]{2}$", "description": "Time of day the entry starts, e.g. 09:00"},
+          "End": {"type": "string", "pattern": "^[0-9]{1,2}:[0-9]{2}$", "description": "Time of day the entry ends, excluded. May be before Start to wrap past midnight"}
+        }
+      },
+      "ScheduleChange": {
+        "type": "object",
+        "properties": {
+          "Time": {"type": "string", "format": "date-time"},
+          "Entry": {"type": "integer", "description": "Index of the entry active from Time, -1 for none"},
+          "Preset": {"type": "string", "description": "Empty when no entry is active from Time"}
+        }
+      },
+      "Schedule": {
+        "type": "object",
+        "properties": {
+          "Entries": {"type": "array", "items": {"$ref": "#/components/schemas/ScheduleEntry"}},
+          "Active": {"type": "string", "description": "The preset of the entry active now, empty for none"},
+          "Upcoming": {"type": "array", "items": {"$ref": "#/components/schemas/ScheduleChange"}}
+        }
+      },
+      "Account": {
+        "type": "object",
+        "properties": {
+          "Name": {"type": "string", "pattern": "^[A-Za-z0-9 _-]+$"},
+          "Session": {"$ref": "#/components/schemas/Session"}
+        }
+      },
+      "Session": {
+        "type": "object",
+        "properties": {
+          "State": {"type": "string", "enum": ["logged out", "logged in", "expired", "re-authenticating", "failed"]},
+          "Username": {"type": "string"},
+          "Since": {"type": "string", "format": "date-time", "description": "When the login entered State"},
+          "Detail": {"type": "string", "description": "Why the login failed, or which attempt of logging in again is running"}
+        }
+      },
+      "Traffic": {
+        "type": "object",
+        "properties": {
+          "Requests": {"type": "integer", "description": "Attempts sent, including retries"},
+          "Retries": {"type": "integer"},
+          "GaveUp": {"type": "integer", "description": "Requests that still failed after their last attempt"},
+          "Throttled": {"type": "integer", "description": "Attempts the rate limiter held back"},
+          "ThrottledSeconds": {"type": "number"},
+          "TooManyRequests": {"type": "integer", "description": "429 answers"},
+          "ServerErrors": {"type": "integer", "description": "5xx answers"},
+          "NetworkErrors": {"type": "integer"},
+          "Breaker": {"type": "string", "enum": ["closed", "open", "half-open"]},
+          "BreakerOpens": {"type": "integer"},
+          "OpenUntil": {"type": "string", "format": "date-time", "description": "When an open breaker lets the next trial request through"},
+          "LastError": {"type": "string"},
+          "LastErrorAt": {"type": "string", "format": "date-time"}
+        }
+      },
+      "SlotResult": {
+        "type": "object",
+        "properties": {
+          "Slot": {"type": "string"},
+          "BadgeId": {"type": "string", "description": "The badge picked for the slot, empty to clear it"},
+          "Previous": {"type": "string", "description": "The badge the slot showed before the rotation"},
+          "PreviousKnown": {"type": "boolean", "description": "Whether Previous is known. Without it an updated slot cannot be set back"},
+          "Outcome": {"type": "string", "enum": ["updated", "failed", "rolled back", "rollback failed"]},
+          "Attempts": {"type": "integer", "description": "How often the slot update was tried"},
+          "Updated": {"type": "boolean", "description": "Whether the slot shows BadgeId"},
+          "Verified": {"type": "boolean", "description": "Whether the outcome was confirmed by reading the profile back"},
+          "Error": {"type": "string"}
+        }
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// maxGenerations is how many previous versions of each saved file are kept in
+// backupDir for the loaders to fall back to.
+const maxGenerations = 3
+
+// generationName returns the name, relative to appDir, of an earlier version
+// of file. Generation 1 is the newest. They are kept in the backupDir next to
+// file, so every account has its own.
+func generationName(file string, generation int) string {
+	return filepath.Join(filepath.Dir(file), backupDir, fmt.Sprintf("%s.%d", filepath.Base(file), generation))
+}
+
+// writeFileAtomic replaces file in appDir with data so that a crash or a full
+// disk leaves either the old or the new contents, never a mix. The data goes
+// to a temporary file that is synced and then renamed over file. The
+// previous contents are first rotated into the generations in the backupDir
+// next to file.
+func writeFileAtomic(file string, data []byte) error {
+	fileName := filepath.Join(appDir, file)
+	tmpFile, err := ioutil.TempFile(filepath.Dir(fileName), "."+filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmpFile.Name()
+	// Only does something when an error stops us before the rename
+	defer os.Remove(tmpName)
+
+	_, err = tmpFile.Write(data)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	err = rotateGenerations(file)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmpName, fileName)
+	if err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(fileName))
+	return nil
+}
+
+// rotateGenerations shifts the saved generations of file down by one,
+// dropping the oldest, and copies the current file in as generation 1.
+func rotateGenerations(file string) error {
+	current, err := ioutil.ReadFile(filepath.Join(appDir, file))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Join(appDir, filepath.Dir(generationName(file, 1))), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	for generation := maxGenerations - 1; generation >= 1; generation-- {
+		err = os.Rename(filepath.Join(appDir, generationName(file, generation)), filepath.Join(appDir, generationName(file, generation+1)))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	// Only the user may read the copies, as the live files, since the session
+	// holds the login cookies
+	return ioutil.WriteFile(filepath.Join(appDir, generationName(file, 1)), current, 0600)
+}
+
+// syncDir makes a rename in dir durable. Not every platform can sync a
+// directory, so errors are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// readGenerations hands the contents of file in appDir to decode. When that
+// fails it tries each saved generation, newest first, tells notify which one
+// was loaded, and returns the generation that decoded, 0 being file itself.
+// If none do, the error of file itself is returned. A missing file is not
+// recovered from its generations since it may have been deleted on purpose.
+func readGenerations(file string, decode func(data []byte) error, notify func(message string)) (int, error) {
+	data, err := ioutil.ReadFile(filepath.Join(appDir, file))
+	if err != nil {
+		return 0, err
+	}
+	firstErr := decode(data)
+	if firstErr == nil {
+		return 0, nil
+	}
+	for generation := 1; generation <= maxGenerations; generation++ {
+		data, err = ioutil.ReadFile(filepath.Join(appDir, generationName(file, generation)))
+		if err != nil {
+			continue
+		}
+		if decode(data) == nil {
+			notify(fmt.Sprintf("%s could not be read (%s). Loaded the copy saved %d write(s) earlier instead", file, firstErr.Error(), generation))
+			return generation, nil
+		}
+	}
+	return 0, firstErr
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	errInvalidPresetName = errors.New("Preset names may only contain letters, digits, spaces, '-' and '_'")
+	errPresetExists      = errors.New("A preset with that name already exists")
+)
+
+var presetNamePattern = regexp.MustCompile(`^[A-Za-z0-9 _-]+$`)
+
+// validPresetName reports whether name can be used as a preset name. The
+// file storage puts it in a file name, so it must not be able to leave
+// appDir.
+func validPresetName(name string) bool {
+	return presetNamePattern.MatchString(name)
+}
+
+// storedPresetName reports whether name can refer to a saved preset. Older
+// versions saved presets under any name, so existing ones are only refused
+// when they could leave appDir; validPresetName applies to new names.
+func storedPresetName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\\\x00")
+}
+
+func (a *account) presetExists(name string) bool {
+	for _, v := range a.getPresets() {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// presetSlots lists the badge Ids a preset selects for each slot.
+func presetSlots(badges map[string]*microBadge) map[string][]string {
+	slots := map[string][]string{}
+	for id, mb := range badges {
+		for i, sel := range mb.Selected {
+			if sel {
+				slotID := strconv.Itoa(i + 1)
+				slots[slotID] = append(slots[slotID], id)
+			}
+		}
+	}
+	for _, ids := range slots {
+		sort.Strings(ids)
+	}
+	return slots
+}
+
+func (a *account) savePreset(name string) error {
+	if !validPresetName(name) {
+		return errInvalidPresetName
+	}
+	return a.store.SavePreset(name, a.newMBFile(a.state.badgesSnapshot()))
+}
+
+func (a *account) renamePreset(name, newName string) error {
+	if !storedPresetName(name) || !validPresetName(newName) {
+		return errInvalidPresetName
+	}
+	return a.store.RenamePreset(name, newName)
+}
+
+// duplicatePreset saves a copy of a preset under newName.
+func (a *account) duplicatePreset(name, newName string) error {
+	if !storedPresetName(name) || !validPresetName(newName) {
+		return errInvalidPresetName
+	}
+	if a.presetExists(newName) {
+		return errPresetExists
+	}
+	preset, err := a.store.LoadPreset(name)
+	if err != nil {
+		return err
+	}
+	return a.store.SavePreset(newName, preset)
+}
+
+func (a *account) deletePreset(name string) error {
+	if !storedPresetName(name) {
+		return errInvalidPresetName
+	}
+	return a.store.DeletePreset(name)
+}
+
+// presetSlotDiff compares what a preset puts in a slot with the current
+// selection. Added are the badges only the preset selects, Removed the ones
+// only the current selection does.
+type presetSlotDiff struct {
+	Slot    string
+	Badges  []string
+	Added   []string
+	Removed []string
+}
+
+// diffPreset compares every slot of a preset with the current selections.
+func (a *account) diffPreset(preset, current map[string]*microBadge) []presetSlotDiff {
+	presetSelections := presetSlots(preset)
+	currentSelections := presetSlots(current)
+	diffs := []presetSlotDiff{}
+	for _, slotID := range a.state.slotIDs() {
+		diff := presetSlotDiff{Slot: slotID, Badges: []string{}, Added: []string{}, Removed: []string{}}
+		inPreset := map[string]bool{}
+		for _, id := range presetSelections[slotID] {
+			inPreset[id] = true
+			diff.Badges = append(diff.Badges, id)
+		}
+		inCurrent := map[string]bool{}
+		for _, id := range currentSelections[slotID] {
+			inCurrent[id] = true
+			if !inPreset[id] {
+				diff.Removed = append(diff.Removed, id)
+			}
+		}
+		for _, id := range diff.Badges {
+			if !inCurrent[id] {
+				diff.Added = append(diff.Added, id)
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// presetErrorStatus picks the HTTP status for an error from the preset
+// functions.
+func presetErrorStatus(err error) int {
+	switch err {
+	case errInvalidPresetName:
+		return http.StatusBadRequest
+	case errNotFound:
+		return http.StatusNotFound
+	case errPresetExists:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func presetErrorMessage(err error) string {
+	if err == errNotFound {
+		return "The requested preset does not exist"
+	}
+	return err.Error()
+}
+
+// presetHandler shows which badges a preset puts in each slot and how that
+// differs from the current selection, with forms to rename, duplicate and
+// delete it.
+func presetHandler(w http.ResponseWriter, r *http.Request) {
+	presetPage := `
+<html>
+<head>
+<title>MicroBadger preset {{.Name}}</title>
+</head>
+<body>
+<a href="/">Back</a>
+<h2>Preset {{.Name}}</h2>
+<form action="/presetRename" method="post">
+<input type="hidden" name="preset" value="{{.Name}}" />
+<input type="text" name="new-name" />
+<button type="submit">Rename</button>
+</form>
+<form action="/presetDuplicate" method="post">
+<input type="hidden" name="preset" value="{{.Name}}" />
+<input type="text" name="new-name" />
+<button type="submit">Duplicate</button>
+</form>
+<form action="/presetDelete" method="post" onSubmit="return confirm('Delete this preset?')">
+<input type="hidden" name="preset" value="{{.Name}}" />
+<button type="submit">Delete</button>
+</form>
+{{range $slot := .Slots}}
+<h3>Slot {{$slot.Slot}}</h3>
+<table>
+<tr><th>In this preset</th><th>Compared with the current selection</th></tr>
+<tr>
+<td>{{range $id := $slot.Badges}}{{with index $.Badges $id}}<img src="{{.ImgURL}}" /> {{.Name}}{{else}}{{$id}}{{end}}<br />{{else}}(cleared){{end}}</td>
+<td>{{range $id := $slot.Added}}+ {{with index $.Badges $id}}{{.Name}}{{else}}{{$id}}{{end}}<br />{{end}}{{range $id := $slot.Removed}}- {{with index $.Badges $id}}{{.Name}}{{else}}{{$id}}{{end}}<br />{{end}}{{if not (or $slot.Added $slot.Removed)}}Same as now{{end}}</td>
+</tr>
+</table>
+{{end}}
+</body>
+</html>
+`
+	name := r.URL.Query().Get("name")
+	if !storedPresetName(name) {
+		http.Error(w, errInvalidPresetName.Error(), http.StatusBadRequest)
+		return
+	}
+	a := requestAccount(r)
+	preset, err := a.store.LoadPreset(name)
+	if err != nil {
+		http.Error(w, presetErrorMessage(err), presetErrorStatus(err))
+		return
+	}
+	tmpl, err := template.New("").Parse(presetPage)
+	if err != nil {
+		fmt.Fprintf(w, "error: "+err.Error())
+		return
+	}
+	current := a.state.badgesSnapshot()
+	// Names of badges the profile no longer has come from the preset
+	badges := map[string]*microBadge{}
+	for id, mb := range preset.Badges {
+		badges[id] = mb
+	}
+	for id, mb := range current {
+		badges[id] = mb
+	}
+	err = tmpl.Execute(w, struct {
+		Name   string
+		Slots  []presetSlotDiff
+		Badges map[string]*microBadge
+	}{name, a.diffPreset(preset.Badges, current), badges})
+	if err != nil {
+		fmt.Fprintf(w, "error: "+err.Error())
+	}
+}
+
+func presetRenameHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	newName := r.Form.Get("new-name")
+	a := requestAccount(r)
+	err := a.renamePreset(r.Form.Get("preset"), newName)
+	if err != nil {
+		http.Error(w, presetErrorMessage(err), presetErrorStatus(err))
+		return
+	}
+	a.state.notify("Preset " + r.Form.Get("preset") + " renamed to " + newName)
+	http.Redirect(w, r, "/preset?name="+url.QueryEscape(newName), http.StatusSeeOther)
+}
+
+func presetDuplicateHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	newName := r.Form.Get("new-name")
+	a := requestAccount(r)
+	err := a.duplicatePreset(r.Form.Get("preset"), newName)
+	if err != nil {
+		http.Error(w, presetErrorMessage(err), presetErrorStatus(err))
+		return
+	}
+	a.state.notify("Preset " + r.Form.Get("preset") + " duplicated as " + newName)
+	http.Redirect(w, r, "/preset?name="+url.QueryEscape(newName), http.StatusSeeOther)
+}
+
+func presetDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	a := requestAccount(r)
+	err := a.deletePreset(r.Form.Get("preset"))
+	if err != nil {
+		http.Error(w, presetErrorMessage(err), presetErrorStatus(err))
+		return
+	}
+	a.state.notify("Preset " + r.Form.Get("preset") + " deleted")
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidPresetName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"weekend":         true,
+		"Game night 2":    true,
+		"week_end-1":      true,
+		"":                false,
+		"v1.2":            false,
+		"Spielgröße":      false,
+		"../selections":   false,
+		"..":              false,
+		"a/b":             false,
+		`..\selections`:   false,
+		"name\x00.mb":     false,
+		"/etc/passwd":     false,
+		"weekend/../../x": false,
+	} {
+		if got := validPresetName(name); got != want {
+			t.Errorf("validPresetName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestStoredPresetName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"weekend":       true,
+		"v1.2":          true,
+		"Spielgröße":    true,
+		"":              false,
+		".":             false,
+		"..":            false,
+		"../selections": false,
+		"a/b":           false,
+		`..\selections`: false,
+		"name\x00.mb":   false,
+	} {
+		if got := storedPresetName(name); got != want {
+			t.Errorf("storedPresetName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestRenamePreset(t *testing.T) {
+	forEachStorage(t, func(t *testing.T) {
+		a, _ := newRotationAccount(t)
+		for _, name := range []string{"weekend", "weekday"} {
+			if err := a.savePreset(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := a.renamePreset("weekend", "saturday"); err != nil {
+			t.Fatal(err)
+		}
+		if presets := a.getPresets(); !reflect.DeepEqual(presets, []string{"saturday", "weekday"}) {
+			t.Errorf("the presets are %v after renaming", presets)
+		}
+		for _, test := range []struct {
+			name, newName string
+			want          error
+		}{
+			{"saturday", "weekday", errPresetExists},
+			{"missing", "sunday", errNotFound},
+			{"saturday", "../sunday", errInvalidPresetName},
+			{"../selections", "sunday", errInvalidPresetName},
+			{"saturday", "v2.0", errInvalidPresetName},
+		} {
+			if err := a.renamePreset(test.name, test.newName); err != test.want {
+				t.Errorf("renaming %q to %q gave %v, want %v", test.name, test.newName, err, test.want)
+			}
+		}
+	})
+}
+
+func TestDuplicatePreset(t *testing.T) {
+	forEachStorage(t, func(t *testing.T) {
+		a, _ := newRotationAccount(t)
+		if err := a.savePreset("weekend"); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.duplicatePreset("weekend", "copy"); err != nil {
+			t.Fatal(err)
+		}
+		original, err := a.store.LoadPreset("weekend")
+		if err != nil {
+			t.Fatal(err)
+		}
+		copied, err := a.store.LoadPreset("copy")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(presetSlots(copied.Badges), presetSlots(original.Badges)) {
+			t.Errorf("the copy selects %v, want %v", presetSlots(copied.Badges), presetSlots(original.Badges))
+		}
+		for _, test := range []struct {
+			name, newName string
+			want          error
+		}{
+			{"weekend", "copy", errPresetExists},
+			{"missing", "other", errNotFound},
+			{"weekend", "../selections", errInvalidPresetName},
+			{"../selections", "other", errInvalidPresetName},
+		} {
+			if err := a.duplicatePreset(test.name, test.newName); err != test.want {
+				t.Errorf("duplicating %q as %q gave %v, want %v", test.name, test.newName, err, test.want)
+			}
+		}
+	})
+}
+
+func TestDeletePreset(t *testing.T) {
+	forEachStorage(t, func(t *testing.T) {
+		a, _ := newRotationAccount(t)
+		if err := a.savePreset("weekend"); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.deletePreset("weekend"); err != nil {
+			t.Fatal(err)
+		}
+		if presets := a.getPresets(); len(presets) != 0 {
+			t.Errorf("the presets are %v after deleting", presets)
+		}
+		if err := a.deletePreset("weekend"); err != errNotFound {
+			t.Errorf("deleting a missing preset gave %v", err)
+		}
+		if err := a.deletePreset("../selections"); err != errInvalidPresetName {
+			t.Errorf("deleting ../selections gave %v", err)
+		}
+		if _, err := a.store.LoadSelections(); err != nil {
+			t.Errorf("the selections are gone: %v", err)
+		}
+	})
+}
+
+// TestPresetsWithOldNames covers presets saved before names were checked.
+func TestPresetsWithOldNames(t *testing.T) {
+	forEachStorage(t, func(t *testing.T) {
+		a, _ := newRotationAccount(t)
+		for _, name := range []string{"v1.2", "Spielgröße"} {
+			if err := a.store.SavePreset(name, a.newMBFile(a.state.badgesSnapshot())); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if presets := a.getPresets(); !reflect.DeepEqual(presets, []string{"Spielgröße", "v1.2"}) {
+			t.Fatalf("the presets are %v", presets)
+		}
+		if err := a.loadPreset("v1.2"); err != nil {
+			t.Errorf("loading v1.2 gave %v", err)
+		}
+		if err := a.duplicatePreset("v1.2", "v1-2"); err != nil {
+			t.Errorf("duplicating v1.2 gave %v", err)
+		}
+		if err := a.renamePreset("Spielgröße", "Spielgroesse"); err != nil {
+			t.Errorf("renaming Spielgröße gave %v", err)
+		}
+		if err := a.deletePreset("v1.2"); err != nil {
+			t.Errorf("deleting v1.2 gave %v", err)
+		}
+		if presets := a.getPresets(); !reflect.DeepEqual(presets, []string{"Spielgroesse", "v1-2"}) {
+			t.Errorf("the presets are %v", presets)
+		}
+		if err := a.savePreset("v1.3"); err != errInvalidPresetName {
+			t.Errorf("saving a new preset v1.3 gave %v", err)
+		}
+	})
+}
+
+func TestDiffPreset(t *testing.T) {
+	a, _ := newRotationAccount(t)
+	if err := a.savePreset("before"); err != nil {
+		t.Fatal(err)
+	}
+	err := a.submitCheckedMicroBadges(map[string][]string{"1": {"1"}, "2": {"3", "4", "5"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	preset, err := a.store.LoadPreset("before")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []presetSlotDiff{
+		{Slot: "1", Badges: []string{"1", "2"}, Added: []string{"2"}, Removed: []string{}},
+		{Slot: "2", Badges: []string{"3", "4"}, Added: []string{}, Removed: []string{"5"}},
+		{Slot: "3", Badges: []string{"5", "6"}, Added: []string{"5", "6"}, Removed: []string{}},
+	}
+	if diffs := a.diffPreset(preset.Badges, a.state.badgesSnapshot()); !reflect.DeepEqual(diffs, want) {
+		t.Errorf("the diff is %+v, want %+v", diffs, want)
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Outcomes of a slot in a rotation.
+const (
+	// slotUpdated: the slot shows the planned badge
+	slotUpdated = "updated"
+	// slotFailed: the slot could not be updated and still shows its
+	// previous badge, as far as is known
+	slotFailed = "failed"
+	// slotRolledBack: the slot was updated, then set back to its previous
+	// badge because another slot failed
+	slotRolledBack = "rolled back"
+	// slotRollbackFailed: the slot was updated but setting it back failed,
+	// so it shows the planned badge
+	slotRollbackFailed = "rollback failed"
+)
+
+// rotationAttempts is how often each slot update of a rotation is tried
+// before the rotation is rolled back. Retries of single requests happen
+// below this, in bggTraffic.
+const rotationAttempts = 2
+
+// slotReader is a bggClient that can read back which badge each slot shows
+// on the profile. A rotation is verified against it when the client has it.
+type slotReader interface {
+	AssignedSlots(username string) (map[string]string, error)
+}
+
+// slotResult is the outcome of one slot of a rotation. Previous is the badge
+// the slot showed before, and PreviousKnown tells whether that is known:
+// without a slotReader it is not until microBadger has assigned the slot
+// once, in this run or in one the rotation history remembers. Updated is
+// set when the slot shows BadgeId, and Verified when the profile was read
+// back to confirm the outcome.
+type slotResult struct {
+	Slot          string
+	BadgeId       string
+	Previous      string
+	PreviousKnown bool
+	Outcome       string
+	Attempts      int
+	Updated       bool
+	Verified      bool
+	Error         string `json:",omitempty"`
+}
+
+// planRotation returns a result for every slot with the badge picked for it
+// and the badge it shows now, read from the profile when the client can.
+func (a *account) planRotation(badgeList []microBadge) []slotResult {
+	shown, err := a.assignedSlots()
+	if err != nil {
+		a.state.notify("Reading the slots from the profile failed, so the rotation is not verified: " + err.Error())
+	}
+	results := make([]slotResult, len(badgeList))
+	for i, v := range badgeList {
+		slotID := fmt.Sprintf("%d", i+1)
+		results[i] = slotResult{Slot: slotID, BadgeId: v.Id, Outcome: slotFailed}
+		if shown != nil {
+			results[i].Previous = shown[slotID]
+			results[i].PreviousKnown = true
+		} else {
+			results[i].Previous, results[i].PreviousKnown = a.state.assignedBadgeID(slotID)
+		}
+	}
+	return results
+}
+
+// assignedSlots reads the slot assignments from the profile, or returns nil
+// when the client cannot.
+func (a *account) assignedSlots() (map[string]string, error) {
+	reader, ok := a.bgg.(slotReader)
+	if !ok {
+		return nil, nil
+	}
+	return reader.AssignedSlots(a.state.Username())
+}
+
+// rotate applies a planned rotation as a unit. Failed slots are tried again,
+// and the outcome is checked against the profile. If a slot still fails, the
+// slots already updated are set back to their previous badges, so the
+// profile is left as it was rather than half rotated.
+func (a *account) rotate(results []slotResult) {
+	for attempt := 1; attempt <= rotationAttempts; attempt++ {
+		for i := range results {
+			r := &results[i]
+			if r.Updated {
+				continue
+			}
+			r.Attempts++
+			if err := a.assignSlot(r.BadgeId, r.Slot); err != nil {
+				r.Error = err.Error()
+				continue
+			}
+			r.Updated = true
+			r.Outcome = slotUpdated
+			r.Error = ""
+		}
+		a.verifyRotation(results)
+		if rotationApplied(results) {
+			break
+		}
+	}
+	if rotationApplied(results) {
+		return
+	}
+
+	for i := range results {
+		r := &results[i]
+		if !r.Updated {
+			continue
+		}
+		if !r.PreviousKnown {
+			r.Outcome = slotRollbackFailed
+			r.Error = "the previous badge is not known"
+			continue
+		}
+		if err := a.assignSlot(r.Previous, r.Slot); err != nil {
+			r.Outcome = slotRollbackFailed
+			r.Error = "setting the previous badge back failed: " + err.Error()
+			continue
+		}
+		r.Updated = false
+		r.Outcome = slotRolledBack
+	}
+	a.verifyRotation(results)
+}
+
+// verifyRotation reads the profile back and marks every slot that does not
+// show what its outcome says as failed. It reports false when the profile
+// could not be read.
+func (a *account) verifyRotation(results []slotResult) bool {
+	shown, err := a.assignedSlots()
+	if err != nil {
+		a.state.notify("Reading the slots from the profile failed, so the rotation is not verified: " + err.Error())
+	}
+	if shown == nil {
+		for i := range results {
+			results[i].Verified = false
+		}
+		return false
+	}
+	for i := range results {
+		r := &results[i]
+		r.Verified = true
+		if !r.Updated && r.Outcome == slotFailed && shown[r.Slot] == r.BadgeId {
+			// The update went through although its answer said otherwise
+			a.state.setAssignedBadge(r.Slot, r.BadgeId)
+			r.Updated = true
+			r.Outcome = slotUpdated
+			r.Error = ""
+			continue
+		}
+		want := r.BadgeId
+		if !r.Updated {
+			want = r.Previous
+		}
+		if shown[r.Slot] == want || !r.Updated && !r.PreviousKnown {
+			continue
+		}
+		a.state.setAssignedBadge(r.Slot, shown[r.Slot])
+		if r.Updated {
+			r.Updated = false
+			r.Outcome = slotFailed
+			r.Error = "the profile shows " + badgeOrNone(shown[r.Slot]) + " instead"
+		} else if r.Outcome == slotRolledBack {
+			r.Outcome = slotRollbackFailed
+			r.Error = "the profile shows " + badgeOrNone(shown[r.Slot]) + " instead of the previous badge"
+		}
+	}
+	return true
+}
+
+func rotationApplied(results []slotResult) bool {
+	for _, r := range results {
+		if !r.Updated {
+			return false
+		}
+	}
+	return true
+}
+
+func badgeOrNone(badgeID string) string {
+	if badgeID == "" {
+		return "no badge"
+	}
+	return "badge " + badgeID
+}
+
+// rotationMessage sums up the outcome of a rotation for the notifications.
+func rotationMessage(results []slotResult) string {
+	byOutcome := map[string][]string{}
+	errs := []string{}
+	for _, r := range results {
+		byOutcome[r.Outcome] = append(byOutcome[r.Outcome], r.Slot)
+		if r.Outcome == slotFailed || r.Outcome == slotRollbackFailed {
+			errs = append(errs, "slot "+r.Slot+": "+r.Error)
+		}
+	}
+	if len(results) == 0 {
+		return "Slots not updated"
+	}
+	if rotationApplied(results) {
+		return "Slots " + strings.Join(byOutcome[slotUpdated], " ") + " updated successfully"
+	}
+	message := "Slots " + strings.Join(byOutcome[slotFailed], " ") + " could not be updated"
+	if rolledBack := byOutcome[slotRolledBack]; len(rolledBack) > 0 {
+		message += ", so slots " + strings.Join(rolledBack, " ") + " were set back to their previous badges"
+	}
+	if stuck := byOutcome[slotRollbackFailed]; len(stuck) > 0 {
+		message += ". Slots " + strings.Join(stuck, " ") + " keep their new badges, as they could not be set back"
+	}
+	return message + " (" + strings.Join(errs, "; ") + ")"
+}
+
+// recordRotation adds the slots a rotation updated to the rotation history.
+func (a *account) recordRotation(results []slotResult, now time.Time) {
+	for _, r := range results {
+		if r.Updated {
+			a.state.recordHistory(r.Slot, r.BadgeId, now)
+		}
+	}
+	if err := a.saveHistory(); err != nil {
+		a.state.notify(err.Error())
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// blindBGG hides AssignedSlots of the client it wraps, like a profile page
+// that cannot be read.
+type blindBGG struct {
+	bggClient
+}
+
+// scriptedBGG answers slot updates with setSlot instead of the fake.
+type scriptedBGG struct {
+	*fakeBGG
+	setSlot func(slotNumber, badgeID string) error
+}
+
+func (s scriptedBGG) SetSlot(slotNumber, badgeID string) error {
+	return s.setSlot(slotNumber, badgeID)
+}
+
+func TestRotationRollsBack(t *testing.T) {
+	a, fake := newRotationAccount(t)
+	before := a.randomizeBadges()
+	fake.Failures["SetSlot 3"] = errors.New("rejected")
+
+	results := a.randomizeBadges()
+	want := []string{slotRolledBack, slotRolledBack, slotFailed}
+	for i, r := range results {
+		if r.Outcome != want[i] || r.Updated || !r.Verified || !r.PreviousKnown || r.Previous != before[i].BadgeId {
+			t.Errorf("slot %s: %+v, want %s back to %s", r.Slot, r, want[i], before[i].BadgeId)
+		}
+		if fake.Slots[r.Slot] != before[i].BadgeId {
+			t.Errorf("slot %s shows %q, want %q", r.Slot, fake.Slots[r.Slot], before[i].BadgeId)
+		}
+	}
+	if results[2].Attempts != rotationAttempts {
+		t.Errorf("the failing slot was tried %d times, want %d", results[2].Attempts, rotationAttempts)
+	}
+	message := rotationMessage(results)
+	if !strings.Contains(message, "Slots 3 could not be updated, so slots 1 2 were set back") {
+		t.Errorf("message %q", message)
+	}
+}
+
+func TestRotationRetriesFailedSlot(t *testing.T) {
+	fake := newFakeBGG(nil)
+	failures := 1
+	a := newTestAccount(t, scriptedBGG{fakeBGG: fake, setSlot: func(slotNumber, badgeID string) error {
+		if slotNumber == "2" && failures > 0 {
+			failures--
+			return errors.New("timeout")
+		}
+		return fake.SetSlot(slotNumber, badgeID)
+	}})
+	setUpRotation(t, a)
+
+	results := a.randomizeBadges()
+	for i, r := range results {
+		wantAttempts := 1
+		if r.Slot == "2" {
+			wantAttempts = 2
+		}
+		if r.Outcome != slotUpdated || !r.Verified || r.Attempts != wantAttempts || r.Error != "" {
+			t.Errorf("slot %s: %+v", r.Slot, r)
+		}
+		if fake.Slots[r.Slot] != results[i].BadgeId {
+			t.Errorf("slot %s shows %q, want %q", r.Slot, fake.Slots[r.Slot], results[i].BadgeId)
+		}
+	}
+}
+
+func TestRotationVerifiesAgainstProfile(t *testing.T) {
+	// Slot 1 is updated although the answer said otherwise, slot 3 answers
+	// success but keeps its badge
+	fake := newFakeBGG(nil)
+	a := newTestAccount(t, scriptedBGG{fakeBGG: fake, setSlot: func(slotNumber, badgeID string) error {
+		switch slotNumber {
+		case "1":
+			fake.SetSlot(slotNumber, badgeID)
+			return errors.New("timeout")
+		case "3":
+			return nil
+		}
+		return fake.SetSlot(slotNumber, badgeID)
+	}})
+	setUpRotation(t, a)
+
+	results := a.randomizeBadges()
+	want := []string{slotRolledBack, slotRolledBack, slotFailed}
+	for i, r := range results {
+		if r.Outcome != want[i] || !r.Verified {
+			t.Errorf("slot %s: %+v, want %s", r.Slot, r, want[i])
+		}
+	}
+	if !strings.Contains(results[2].Error, "the profile shows no badge instead") {
+		t.Errorf("slot 3: %q", results[2].Error)
+	}
+	// The profile showed no badges before
+	for slotID, badgeID := range fake.Slots {
+		if badgeID != "" {
+			t.Errorf("slot %s shows %q after the rollback", slotID, badgeID)
+		}
+	}
+}
+
+func TestRotationRollsBackAfterRestart(t *testing.T) {
+	fake := newFakeBGG(nil)
+	a := newTestAccount(t, blindBGG{fake})
+	setUpRotation(t, a)
+	before := a.randomizeBadges()
+
+	restarted := reopenAccount(t, blindBGG{fake})
+	setUpRotation(t, restarted)
+	restarted.loadHistory()
+	fake.Failures["SetSlot 2"] = errors.New("rejected")
+	results := restarted.randomizeBadges()
+	want := []string{slotRolledBack, slotFailed, slotRolledBack}
+	for i, r := range results {
+		if r.Outcome != want[i] || r.Verified || !r.PreviousKnown || r.Previous != before[i].BadgeId {
+			t.Errorf("slot %s: %+v, want %s back to %s", r.Slot, r, want[i], before[i].BadgeId)
+		}
+		if fake.Slots[r.Slot] != before[i].BadgeId {
+			t.Errorf("slot %s shows %q, want %q", r.Slot, fake.Slots[r.Slot], before[i].BadgeId)
+		}
+	}
+
+	// Without a history the previous badges are not known
+	fresh := newTestAccount(t, blindBGG{fake})
+	setUpRotation(t, fresh)
+	results = fresh.randomizeBadges()
+	want = []string{slotRollbackFailed, slotFailed, slotRollbackFailed}
+	for i, r := range results {
+		if r.Outcome != want[i] || r.PreviousKnown {
+			t.Errorf("slot %s without history: %+v, want %s", r.Slot, r, want[i])
+		}
+	}
+	if message := rotationMessage(results); !strings.Contains(message, "Slots 1 3 keep their new badges") {
+		t.Errorf("message %q", message)
+	}
+}
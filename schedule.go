@@ -0,0 +1,419 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const scheduleFile = "schedule.mb"
+
+// scheduleHorizon is how far ahead upcoming looks for changes. Every window
+// repeats at least yearly, so nothing new happens beyond it.
+const scheduleHorizon = 367
+
+// scheduleRecheck is the longest the scheduler sleeps, so that a clock change
+// or a suspended machine delays a switch by at most this long.
+const scheduleRecheck = time.Minute
+
+// scheduleEntry maps a recurring time window to a preset. Every condition
+// that is set must hold at the same moment. From and Until are month-day
+// dates like "12-20", both included, and may wrap over the new year.
+// Weekdays are names like "Sat". Start and End are times like "09:00", End
+// excluded, and may wrap past midnight. An entry without conditions is always
+// active, which makes a low priority one the default.
+type scheduleEntry struct {
+	Name     string
+	Preset   string
+	Priority int
+	From     string   `json:",omitempty"`
+	Until    string   `json:",omitempty"`
+	Weekdays []string `json:",omitempty"`
+	Start    string   `json:",omitempty"`
+	End      string   `json:",omitempty"`
+}
+
+// schedule holds the entries in the order they were added. When entries
+// overlap the one with the highest Priority wins, and of equal priorities the
+// earlier one.
+type schedule []scheduleEntry
+
+// scheduleWindow is a parsed scheduleEntry.
+type scheduleWindow struct {
+	// from and until are month*100 + day, 0 when the entry has no dates
+	from, until int
+	weekdays    map[time.Weekday]bool
+	// start and end are minutes after midnight, -1 when the entry has no
+	// times
+	start, end int
+}
+
+// parseWeekday takes a weekday name like "Sat" or "Saturday" in any case.
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(name)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		fullName := strings.ToLower(day.String())
+		if name == fullName || name == fullName[:3] {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
+
+func (e scheduleEntry) window() (scheduleWindow, error) {
+	w := scheduleWindow{start: -1, end: -1}
+	if (e.From == "") != (e.Until == "") {
+		return w, errors.New("From and Until must be given together")
+	}
+	if e.From != "" {
+		from, err := time.Parse("01-02", e.From)
+		if err != nil {
+			return w, errors.New("From must be a date like 12-20")
+		}
+		until, err := time.Parse("01-02", e.Until)
+		if err != nil {
+			return w, errors.New("Until must be a date like 01-02")
+		}
+		w.from = int(from.Month())*100 + from.Day()
+		w.until = int(until.Month())*100 + until.Day()
+	}
+	if len(e.Weekdays) > 0 {
+		w.weekdays = map[time.Weekday]bool{}
+		for _, name := range e.Weekdays {
+			day, ok := parseWeekday(name)
+			if !ok {
+				return w, errors.New("unknown weekday " + name)
+			}
+			w.weekdays[day] = true
+		}
+	}
+	if (e.Start == "") != (e.End == "") {
+		return w, errors.New("Start and End must be given together")
+	}
+	if e.Start != "" {
+		start, err := time.Parse("15:04", e.Start)
+		if err != nil {
+			return w, errors.New("Start must be a time like 09:00")
+		}
+		end, err := time.Parse("15:04", e.End)
+		if err != nil {
+			return w, errors.New("End must be a time like 17:00")
+		}
+		w.start = start.Hour()*60 + start.Minute()
+		w.end = end.Hour()*60 + end.Minute()
+		if w.start == w.end {
+			return w, errors.New("Start and End must differ")
+		}
+	}
+	return w, nil
+}
+
+func (w scheduleWindow) contains(t time.Time) bool {
+	if w.from != 0 {
+		day := int(t.Month())*100 + t.Day()
+		if w.from <= w.until {
+			if day < w.from || day > w.until {
+				return false
+			}
+		} else if day < w.from && day > w.until {
+			return false
+		}
+	}
+	if w.weekdays != nil && !w.weekdays[t.Weekday()] {
+		return false
+	}
+	if w.start >= 0 {
+		minute := t.Hour()*60 + t.Minute()
+		if w.start < w.end {
+			if minute < w.start || minute >= w.end {
+				return false
+			}
+		} else if minute < w.start && minute >= w.end {
+			return false
+		}
+	}
+	return true
+}
+
+// validate checks every entry, naming the first bad one.
+func (s schedule) validate() error {
+	for i, e := range s {
+		if !storedPresetName(e.Preset) {
+			return fmt.Errorf("entry %d: %s", i+1, errInvalidPresetName.Error())
+		}
+		if _, err := e.window(); err != nil {
+			return fmt.Errorf("entry %d: %s", i+1, err.Error())
+		}
+	}
+	return nil
+}
+
+func (s schedule) windows() []scheduleWindow {
+	windows := make([]scheduleWindow, len(s))
+	for i, e := range s {
+		// Entries are validated before they are stored, so this cannot fail
+		windows[i], _ = e.window()
+	}
+	return windows
+}
+
+// activeAt returns the index of the entry active at t, or -1 when none is.
+func (s schedule) activeAt(t time.Time) int {
+	return s.activeIn(s.windows(), t)
+}
+
+func (s schedule) activeIn(windows []scheduleWindow, t time.Time) int {
+	active := -1
+	for i, w := range windows {
+		if w.contains(t) && (active < 0 || s[i].Priority > s[active].Priority) {
+			active = i
+		}
+	}
+	return active
+}
+
+// scheduleChange is a moment the active entry changes. Entry is -1 and
+// Preset empty when no entry is active from then on.
+type scheduleChange struct {
+	Time   time.Time
+	Entry  int
+	Preset string
+}
+
+// upcoming returns the next changes of the active entry after t, at most
+// limit of them. Entries only change at midnight or at one of their Start
+// and End times, so only those moments are checked.
+func (s schedule) upcoming(t time.Time, limit int) []scheduleChange {
+	changes := []scheduleChange{}
+	if len(s) == 0 {
+		return changes
+	}
+	windows := s.windows()
+	current := s.activeIn(windows, t)
+	for day := 0; day < scheduleHorizon && len(changes) < limit; day++ {
+		moments := []time.Time{time.Date(t.Year(), t.Month(), t.Day()+day, 0, 0, 0, 0, t.Location())}
+		for _, w := range windows {
+			if w.start >= 0 {
+				moments = append(moments,
+					time.Date(t.Year(), t.Month(), t.Day()+day, w.start/60, w.start%60, 0, 0, t.Location()),
+					time.Date(t.Year(), t.Month(), t.Day()+day, w.end/60, w.end%60, 0, 0, t.Location()))
+			}
+		}
+		sort.Slice(moments, func(i, j int) bool { return moments[i].Before(moments[j]) })
+		for _, moment := range moments {
+			if !moment.After(t) {
+				continue
+			}
+			active := s.activeIn(windows, moment)
+			if active == current {
+				continue
+			}
+			change := scheduleChange{Time: moment, Entry: active}
+			if active >= 0 {
+				change.Preset = s[active].Preset
+			}
+			changes = append(changes, change)
+			current = active
+			if len(changes) == limit {
+				break
+			}
+		}
+	}
+	return changes
+}
+
+func (a *account) loadSchedule() {
+	loadedSchedule, err := a.store.LoadSchedule()
+	if err == errNotFound {
+		return
+	}
+	if err == nil {
+		err = loadedSchedule.validate()
+	}
+	if err != nil {
+		a.state.notify("Error loading the schedule: " + err.Error())
+		return
+	}
+	a.state.setSchedule(loadedSchedule)
+}
+
+// updateSchedule validates, saves and applies new schedule entries.
+func (a *account) updateSchedule(s schedule) error {
+	return a.editSchedule(func(schedule) (schedule, error) {
+		return s, nil
+	})
+}
+
+// editSchedule validates, saves and applies the entries edit makes of the
+// current ones. Edits run one at a time.
+func (a *account) editSchedule(edit func(current schedule) (schedule, error)) error {
+	a.scheduleMu.Lock()
+	defer a.scheduleMu.Unlock()
+	s, err := edit(a.state.scheduleSnapshot())
+	if err != nil {
+		return err
+	}
+	if err := s.validate(); err != nil {
+		return err
+	}
+	if err := a.store.SaveSchedule(s); err != nil {
+		return err
+	}
+	a.state.setSchedule(s)
+	a.scheduler.wakeUp()
+	return nil
+}
+
+// applySchedulePreset loads the preset of the schedule entry that became
+// active. An empty preset means none is active any more, which leaves the
+// selections as they are.
+func (a *account) applySchedulePreset(preset string) {
+	a.state.setScheduledPreset(preset)
+	if preset == "" {
+		a.state.notify("No schedule entry is active, keeping the current selections")
+		return
+	}
+	if !a.presetExists(preset) {
+		a.state.notify("The scheduled preset " + preset + " does not exist")
+		return
+	}
+	a.state.notify("Schedule: loading " + preset + " preset")
+	a.loadPreset(preset)
+}
+
+func scheduleHandler(w http.ResponseWriter, r *http.Request) {
+	schedulePage := `
+<html>
+<head>
+<title>MicroBadger schedule</title>
+</head>
+<body>
+<a href="/">Back</a>
+<h2>Schedule</h2>
+<p>{{if .Active}}Now active: {{.Active}}{{else}}No entry is active now{{end}}</p>
+<h3>Coming up</h3>
+<table>
+<tr><th>From</th><th>Preset</th></tr>
+{{range .Upcoming}}
+<tr><td>{{.Time.Format "Mon 2006-01-02 15:04"}}</td><td>{{if .Preset}}{{.Preset}}{{else}}(none, the selections stay as they are){{end}}</td></tr>
+{{else}}
+<tr><td colspan="2">Nothing changes within a year</td></tr>
+{{end}}
+</table>
+<h3>Entries</h3>
+<table>
+<tr><th>Name</th><th>Preset</th><th>Priority</th><th>Dates</th><th>Weekdays</th><th>Times</th><th></th></tr>
+{{range $i, $e := .Entries}}
+<tr>
+<td>{{$e.Name}}</td><td>{{$e.Preset}}</td><td>{{$e.Priority}}</td>
+<td>{{if $e.From}}{{$e.From}} to {{$e.Until}}{{else}}every day{{end}}</td>
+<td>{{range $e.Weekdays}}{{.}} {{else}}all{{end}}</td>
+<td>{{if $e.Start}}{{$e.Start}} to {{$e.End}}{{else}}all day{{end}}</td>
+<td><form action="/scheduleDelete" method="post"><input type="hidden" name="entry" value="{{$i}}" /><button type="submit">Delete</button></form></td>
+</tr>
+{{end}}
+</table>
+<h3>Add an entry</h3>
+<form action="/scheduleAdd" method="post">
+Name: <input type="text" name="name" /><br />
+Preset: <select name="preset">{{range .Presets}}<option value="{{.}}">{{.}}</option>{{end}}</select><br />
+Priority: <input type="number" name="priority" value="0" /> (higher wins when entries overlap)<br />
+Dates: <input type="text" name="from" placeholder="12-20" /> to <input type="text" name="until" placeholder="01-02" /> (month-day, leave empty for every day)<br />
+Weekdays: {{range .Weekdays}}<label><input type="checkbox" name="weekday" value="{{.}}" />{{.}}</label> {{end}}(none for every day)<br />
+Times: <input type="text" name="start" placeholder="09:00" /> to <input type="text" name="end" placeholder="17:00" /> (leave empty for all day)<br />
+<button type="submit">Add</button>
+</form>
+</body>
+</html>
+`
+	tmpl, err := template.New("").Parse(schedulePage)
+	if err != nil {
+		fmt.Fprintf(w, "error: "+err.Error())
+		return
+	}
+	a := requestAccount(r)
+	currentSchedule := a.state.scheduleSnapshot()
+	now := a.scheduler.clock.Now()
+	active := ""
+	if i := currentSchedule.activeAt(now); i >= 0 {
+		active = currentSchedule[i].Preset
+		if currentSchedule[i].Name != "" {
+			active = currentSchedule[i].Name + " (" + active + ")"
+		}
+	}
+	err = tmpl.Execute(w, struct {
+		Active   string
+		Upcoming []scheduleChange
+		Entries  schedule
+		Presets  []string
+		Weekdays []string
+	}{active, currentSchedule.upcoming(now, 10), currentSchedule, a.getPresets(),
+		[]string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}})
+	if err != nil {
+		fmt.Fprintf(w, "error: "+err.Error())
+	}
+}
+
+func scheduleAddHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	priority := 0
+	if formPriority := r.Form.Get("priority"); formPriority != "" {
+		var err error
+		priority, err = strconv.Atoi(formPriority)
+		if err != nil {
+			http.Error(w, "The priority must be a whole number", http.StatusBadRequest)
+			return
+		}
+	}
+	entry := scheduleEntry{
+		Name:     r.Form.Get("name"),
+		Preset:   r.Form.Get("preset"),
+		Priority: priority,
+		From:     r.Form.Get("from"),
+		Until:    r.Form.Get("until"),
+		Weekdays: r.Form["weekday"],
+		Start:    r.Form.Get("start"),
+		End:      r.Form.Get("end"),
+	}
+	a := requestAccount(r)
+	if !a.presetExists(entry.Preset) {
+		http.Error(w, "The requested preset does not exist", http.StatusBadRequest)
+		return
+	}
+	err := a.editSchedule(func(current schedule) (schedule, error) {
+		return append(current, entry), nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a.state.notify("Schedule entry for preset " + entry.Preset + " added")
+	http.Redirect(w, r, "/schedule", http.StatusSeeOther)
+}
+
+func scheduleDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	a := requestAccount(r)
+	i, parseErr := strconv.Atoi(r.Form.Get("entry"))
+	err := a.editSchedule(func(current schedule) (schedule, error) {
+		if parseErr != nil || i < 0 || i >= len(current) {
+			return nil, errNotFound
+		}
+		return append(current[:i], current[i+1:]...), nil
+	})
+	if err == errNotFound {
+		http.Error(w, "The requested schedule entry does not exist", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	a.state.notify("Schedule entry " + strconv.Itoa(i+1) + " deleted")
+	http.Redirect(w, r, "/schedule", http.StatusSeeOther)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testSchedule has a default, a new-year holiday, weekends, working hours
+// and nights past midnight.
+var testSchedule = schedule{
+	{Name: "default", Preset: "base", Priority: -1},
+	{Name: "holiday", Preset: "holiday", Priority: 10, From: "12-20", Until: "01-02"},
+	{Name: "weekend", Preset: "weekend", Priority: 5, Weekdays: []string{"Sat", "sunday"}},
+	{Name: "work", Preset: "work", Priority: 1, Weekdays: []string{"Mon", "Tue", "Wed", "Thu", "Fri"}, Start: "09:00", End: "17:00"},
+	{Name: "night", Preset: "night", Priority: 1, Start: "22:00", End: "02:00"},
+}
+
+func scheduleTime(t *testing.T, value string, loc *time.Location) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestScheduleActiveAt(t *testing.T) {
+	if err := testSchedule.validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		time   string
+		preset string
+	}{
+		{"2026-10-19 08:59", "base"}, // Monday
+		{"2026-10-19 09:00", "work"},
+		{"2026-10-19 16:59", "work"},
+		{"2026-10-19 17:00", "base"},
+		{"2026-10-19 22:00", "night"},
+		{"2026-10-20 00:00", "night"}, // past midnight
+		{"2026-10-20 01:59", "night"},
+		{"2026-10-20 02:00", "base"},
+		{"2026-10-17 12:00", "weekend"}, // Saturday
+		{"2026-10-17 23:00", "weekend"}, // a higher priority beats the night
+		{"2026-12-18 20:00", "base"},    // Friday
+		{"2026-12-20 00:00", "holiday"},
+		{"2026-12-31 23:59", "holiday"}, // over the new year
+		{"2027-01-01 00:00", "holiday"},
+		{"2027-01-02 23:59", "holiday"},
+		{"2027-01-03 00:00", "weekend"}, // Sunday
+	} {
+		got := ""
+		if i := testSchedule.activeAt(scheduleTime(t, test.time, time.UTC)); i >= 0 {
+			got = testSchedule[i].Preset
+		}
+		if got != test.preset {
+			t.Errorf("at %s got %q, want %q", test.time, got, test.preset)
+		}
+	}
+
+	// Of equal priorities the earlier entry wins
+	tie := schedule{{Preset: "first"}, {Preset: "second"}}
+	if i := tie.activeAt(time.Now()); i != 0 {
+		t.Errorf("entry %d won a tie, want the earlier one", i)
+	}
+	if i := (schedule{{Preset: "x", Weekdays: []string{"Mon"}}}).activeAt(scheduleTime(t, "2026-10-20 12:00", time.UTC)); i != -1 {
+		t.Errorf("entry %d is active on a Tuesday", i)
+	}
+}
+
+func TestScheduleUpcoming(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		s     schedule
+		now   string
+		limit int
+		want  []string
+	}{
+		{"working day", testSchedule, "2026-10-16 16:30", 4, []string{
+			"2026-10-16 17:00 base", "2026-10-16 22:00 night", "2026-10-17 00:00 weekend", "2026-10-19 00:00 night"}},
+		{"over the new year", testSchedule, "2026-12-18 18:00", 3, []string{
+			"2026-12-18 22:00 night", "2026-12-19 00:00 weekend", "2026-12-20 00:00 holiday"}},
+		{"holiday ends", testSchedule, "2027-01-02 12:00", 1, []string{
+			"2027-01-03 00:00 weekend"}},
+		{"nothing after the end", schedule{{Preset: "once", From: "10-20", Until: "10-20"}}, "2026-10-20 12:00", 3, []string{
+			"2026-10-21 00:00 ", "2027-10-20 00:00 once", "2027-10-21 00:00 "}},
+		{"empty", schedule{}, "2026-10-20 12:00", 3, []string{}},
+	} {
+		changes := test.s.upcoming(scheduleTime(t, test.now, time.UTC), test.limit)
+		got := []string{}
+		for _, change := range changes {
+			got = append(got, change.Time.Format("2006-01-02 15:04")+" "+change.Preset)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+// TestScheduleDaylightSaving checks that windows follow the wall clock on
+// the days the clocks change.
+func TestScheduleDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone database: ", err)
+	}
+	s := schedule{{Preset: "work", Start: "09:00", End: "17:00"}, {Preset: "late", Start: "01:00", End: "03:00"}}
+	for _, day := range []string{"2026-03-08", "2026-11-01"} {
+		changes := s.upcoming(scheduleTime(t, day+" 00:00", loc), 4)
+		got := []string{}
+		for _, change := range changes {
+			got = append(got, change.Time.In(loc).Format("15:04")+" "+change.Preset)
+		}
+		want := []string{"01:00 late", "03:00 ", "09:00 work", "17:00 "}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s: got %q, want %q", day, got, want)
+		}
+		if i := s.activeAt(scheduleTime(t, day+" 12:00", loc)); i != 0 {
+			t.Errorf("%s 12:00: entry %d is active, want work", day, i)
+		}
+	}
+	// The clocks skip an hour in March and repeat one in November, so the
+	// morning is shorter or longer in real time
+	for day, want := range map[string]time.Duration{"2026-03-08": 8 * time.Hour, "2026-11-01": 10 * time.Hour} {
+		midnight := scheduleTime(t, day+" 00:00", loc)
+		if work := s.upcoming(midnight, 3)[2].Time; work.Sub(midnight) != want {
+			t.Errorf("%s: work starts %v after midnight, want %v", day, work.Sub(midnight), want)
+		}
+	}
+}
+
+func TestScheduleValidate(t *testing.T) {
+	for _, s := range []schedule{
+		{{Preset: "../x"}},
+		{{Preset: "a", From: "12-20"}},
+		{{Preset: "a", From: "13-20", Until: "01-01"}},
+		{{Preset: "a", Weekdays: []string{"Funday"}}},
+		{{Preset: "a", Start: "09:00", End: "09:00"}},
+		{{Preset: "a", Start: "9am", End: "10:00"}},
+	} {
+		if err := s.validate(); err == nil {
+			t.Errorf("%+v is valid", s)
+		}
+	}
+}
+
+func TestConcurrentScheduleEdits(t *testing.T) {
+	a := newTestAccount(t, newFakeBGG(nil))
+	if err := a.savePreset("p"); err != nil {
+		t.Fatal(err)
+	}
+	post := func(handler func(w *httptest.ResponseRecorder, form url.Values), form url.Values) {
+		handler(httptest.NewRecorder(), form)
+	}
+	add := func(w *httptest.ResponseRecorder, form url.Values) {
+		r := httptest.NewRequest("POST", "/scheduleAdd", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		scheduleAddHandler(w, r)
+	}
+	remove := func(w *httptest.ResponseRecorder, form url.Values) {
+		r := httptest.NewRequest("POST", "/scheduleDelete", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		scheduleDeleteHandler(w, r)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			post(add, url.Values{"name": {fmt.Sprint(i)}, "preset": {"p"}})
+		}(i)
+	}
+	wg.Wait()
+	if n := len(a.state.scheduleSnapshot()); n != 20 {
+		t.Fatalf("%d of 20 added entries are left", n)
+	}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			post(remove, url.Values{"entry": {"0"}})
+		}()
+	}
+	wg.Wait()
+	saved, err := a.store.LoadSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(a.state.scheduleSnapshot()); n != 10 || len(saved) != 10 {
+		t.Errorf("%d entries are left and %d saved after deleting 10 of 20", n, len(saved))
+	}
+
+	w := httptest.NewRecorder()
+	remove(w, url.Values{"entry": {"10"}})
+	if w.Code != 404 {
+		t.Errorf("deleting a missing entry: got %d, want 404", w.Code)
+	}
+}
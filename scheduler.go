@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// After a failed sync the scheduler waits retryDelay before trying again,
+// twice as long after each further failure, up to maxRetryDelay.
+const (
+	retryDelay    = 10 * time.Second
+	maxRetryDelay = 10 * time.Minute
+)
+
+// clock tells the scheduler the time and wakes it up. Tests give
+// newRotationScheduler one they control.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// rotationScheduler runs every timed task of an account from one loop: the
+// randomizations, the presets being cycled through and the presets of the
+// calendar schedule.
+// The cycle only moves to its next preset together with a randomization, so
+// each preset is shown for one interval, and a preset loaded by the schedule
+// is randomized into the slots at once. Changing the interval, the cycle or
+// the schedule wakes the loop, so the change takes effect immediately.
+type rotationScheduler struct {
+	account *account
+	clock   clock
+	wake    chan bool
+
+	mu   sync.Mutex
+	rand *rand.Rand
+	// lastRun is when the last successful randomization started, nextRun
+	// when the next one is due. A zero nextRun is due at once.
+	lastRun time.Time
+	nextRun time.Time
+	// failures counts the failed syncs since the last successful one
+	failures int
+	// cycleIndex is the next preset of the cycle to load. cycleRestart is
+	// set when a new cycle was chosen and its first preset is due at once.
+	cycleIndex   int
+	cycleRestart bool
+}
+
+// newRotationScheduler returns a scheduler for a driven by c. seed seeds the
+// jitter.
+func newRotationScheduler(a *account, c clock, seed int64) *rotationScheduler {
+	return &rotationScheduler{
+		account: a,
+		clock:   c,
+		wake:    make(chan bool, 1),
+		rand:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+// wakeUp makes the loop check again what is due. It never blocks.
+func (s *rotationScheduler) wakeUp() {
+	select {
+	case s.wake <- true:
+	default:
+	}
+}
+
+// delay returns the interval, varied by up to -jitter percent. s.mu must be
+// held.
+func (s *rotationScheduler) delay() time.Duration {
+	interval := time.Duration(s.account.state.Interval()) * time.Minute
+	if *jitter <= 0 {
+		return interval
+	}
+	spread := float64(interval) * float64(*jitter) / 100
+	return interval + time.Duration(spread*(2*s.rand.Float64()-1))
+}
+
+// setInterval changes the interval and reschedules the next randomization
+// from the last one, which may make it due at once.
+func (s *rotationScheduler) setInterval(minutes int) {
+	s.account.state.setInterval(minutes)
+	s.mu.Lock()
+	if !s.lastRun.IsZero() && s.failures == 0 {
+		s.nextRun = s.lastRun.Add(s.delay())
+	}
+	s.mu.Unlock()
+	s.wakeUp()
+}
+
+// restartCycle starts the cycle over with the first of the active presets.
+func (s *rotationScheduler) restartCycle() {
+	s.mu.Lock()
+	s.cycleIndex = 0
+	s.cycleRestart = true
+	s.mu.Unlock()
+	s.wakeUp()
+}
+
+// nextRandomization returns when the next randomization is due, or the zero
+// time when the scheduler has not run yet.
+func (s *rotationScheduler) nextRandomization() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nextRun
+}
+
+// step does whatever is due at now and returns when there is something to
+// do next. run calls it each time the loop wakes; tests call it with the
+// times they choose.
+func (s *rotationScheduler) step(now time.Time) time.Time {
+	a := s.account
+	currentSchedule := a.state.scheduleSnapshot()
+	scheduled := ""
+	if active := currentSchedule.activeAt(now); active >= 0 {
+		scheduled = currentSchedule[active].Preset
+	}
+	due := false
+	if scheduled != a.state.scheduledPresetName() {
+		a.applySchedulePreset(scheduled)
+		due = scheduled != ""
+	}
+
+	// An active schedule entry takes precedence over the cycle
+	cycle := a.state.activePresetList()
+	cycling := len(cycle) > 0 && scheduled == ""
+	s.mu.Lock()
+	due = due || !now.Before(s.nextRun) || cycling && s.cycleRestart
+	cyclePreset := ""
+	// A retry randomizes the preset that was loaded for the failed run
+	if cycling && (s.cycleRestart || due && s.failures == 0) {
+		cyclePreset = cycle[s.cycleIndex%len(cycle)]
+		s.cycleIndex++
+	}
+	s.cycleRestart = false
+	s.mu.Unlock()
+
+	if due {
+		if cyclePreset != "" {
+			a.state.notify("loading " + cyclePreset + " preset")
+			a.loadPreset(cyclePreset)
+		}
+		err := a.syncAndRandomize()
+		s.mu.Lock()
+		if err != nil {
+			s.failures++
+			s.nextRun = now.Add(backoffDelay(retryDelay, maxRetryDelay, s.failures, s.rand))
+		} else {
+			s.failures = 0
+			s.lastRun = now
+			s.nextRun = now.Add(s.delay())
+		}
+		s.mu.Unlock()
+	}
+
+	next := s.nextRandomization()
+	if changes := currentSchedule.upcoming(now, 1); len(changes) > 0 && changes[0].Time.Before(next) {
+		next = changes[0].Time
+	}
+	if recheck := now.Add(scheduleRecheck); recheck.Before(next) {
+		next = recheck
+	}
+	return next
+}
+
+// run steps the scheduler whenever something is due or has changed, until
+// ctx is cancelled. A step that is running then finishes first, so no
+// rotation is cut off halfway.
+func (s *rotationScheduler) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		next := s.step(s.clock.Now())
+		select {
+		case <-s.clock.After(next.Sub(s.clock.Now())):
+		case <-s.wake:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// releaseCheck looks up the latest release before each randomization.
+// Tests replace it so they stay offline.
+var releaseCheck = checkForUpdates
+
+// syncAndRandomize merges a fresh scrape of the profile into the badges and
+// randomizes the slots.
+func (a *account) syncAndRandomize() error {
+	release.set(releaseCheck())
+	a.state.notify("Attempting to randomize badges: ")
+	err := a.getMicroBadges()
+	if err != nil {
+		a.state.notify("Failed")
+		a.state.notify(err.Error())
+		return err
+	}
+	a.randomizeBadges()
+	return nil
+}
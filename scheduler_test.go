@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when a test advances it.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := fakeTimer{at: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		timer.c <- c.now
+	} else {
+		c.timers = append(c.timers, timer)
+	}
+	return timer.c
+}
+
+// advance moves the clock on by d and fires the timers that are due.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+		} else {
+			timer.c <- c.now
+		}
+	}
+	c.timers = pending
+}
+
+func (c *fakeClock) waiting() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// newSchedulerAccount returns an account logged in to a fake serving the
+// test profile, with badge 1001 selected for slot 1, the presets one (1001),
+// two (1002) and three (3001), and a scheduler driven by a fake clock set
+// to Monday 2026-10-19 08:30.
+func newSchedulerAccount(t *testing.T) (*account, *fakeBGG, *fakeClock) {
+	t.Helper()
+	page, err := ioutil.ReadFile("testdata/microbadges/profile.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeBGG(page)
+	a := newTestAccount(t, fake)
+	if err := a.loginWith(staticCredentials{name: "the test", login: credentials{"user", "pw"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.getMicroBadges(); err != nil {
+		t.Fatal(err)
+	}
+	for preset, id := range map[string]string{"one": "1001", "two": "1002", "three": "3001"} {
+		a.submitCheckedMicroBadges(map[string][]string{"1": {id}})
+		if err := a.savePreset(preset); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.submitCheckedMicroBadges(map[string][]string{"1": {"1001"}})
+	a.state.setInterval(10)
+
+	clock := &fakeClock{now: time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)}
+	a.scheduler = newRotationScheduler(a, clock, 1)
+	saved := releaseCheck
+	releaseCheck = func() string { return VERSION }
+	t.Cleanup(func() { releaseCheck = saved })
+	return a, fake, clock
+}
+
+// rotations counts the updates of slot 1 the fake received.
+func rotations(fake *fakeBGG) int {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	n := 0
+	for _, call := range fake.Calls {
+		if strings.HasPrefix(call, "SetSlot 1 ") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSchedulerInterval(t *testing.T) {
+	a, fake, clock := newSchedulerAccount(t)
+	start := clock.Now()
+
+	next := a.scheduler.step(start)
+	if rotations(fake) != 1 {
+		t.Fatalf("the first step rotated %d times, want once", rotations(fake))
+	}
+	if got := a.scheduler.nextRandomization(); !got.Equal(start.Add(10 * time.Minute)) {
+		t.Errorf("the next randomization is at %v, want 10 minutes after the first", got)
+	}
+	if !next.Equal(start.Add(scheduleRecheck)) {
+		t.Errorf("step wants to wake at %v, want after %v", next, scheduleRecheck)
+	}
+
+	a.scheduler.step(start.Add(9 * time.Minute))
+	if rotations(fake) != 1 {
+		t.Error("rotated before the interval passed")
+	}
+	a.scheduler.step(start.Add(10 * time.Minute))
+	if rotations(fake) != 2 {
+		t.Error("did not rotate when the interval passed")
+	}
+
+	// A shorter interval counts from the last randomization
+	a.scheduler.setInterval(3)
+	if got := a.scheduler.nextRandomization(); !got.Equal(start.Add(13 * time.Minute)) {
+		t.Errorf("the next randomization is at %v, want 3 minutes after the last", got)
+	}
+	a.scheduler.step(start.Add(12 * time.Minute))
+	if rotations(fake) != 2 {
+		t.Error("rotated before the new interval passed")
+	}
+	a.scheduler.step(start.Add(13 * time.Minute))
+	if rotations(fake) != 3 {
+		t.Error("did not rotate after the new interval")
+	}
+}
+
+func TestSchedulerBackoff(t *testing.T) {
+	a, fake, clock := newSchedulerAccount(t)
+	now := clock.Now()
+	fake.Failures["FetchMicrobadges"] = errors.New("unavailable")
+
+	// Each failure waits between half and all of a doubling delay
+	for failures := 1; failures <= 10; failures++ {
+		a.scheduler.step(now)
+		limit := retryDelay << uint(failures-1)
+		if limit > maxRetryDelay {
+			limit = maxRetryDelay
+		}
+		wait := a.scheduler.nextRandomization().Sub(now)
+		if wait < limit/2 || wait > limit {
+			t.Fatalf("failure %d waits %v, want %v to %v", failures, wait, limit/2, limit)
+		}
+		now = now.Add(wait)
+	}
+	if rotations(fake) != 0 {
+		t.Fatal("rotated although the sync failed")
+	}
+
+	delete(fake.Failures, "FetchMicrobadges")
+	a.scheduler.step(now)
+	if rotations(fake) != 1 {
+		t.Fatal("did not rotate once the sync worked again")
+	}
+	if wait := a.scheduler.nextRandomization().Sub(now); wait != 10*time.Minute {
+		t.Errorf("waits %v after a success, want the interval", wait)
+	}
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	a, _, _ := newSchedulerAccount(t)
+	a.state.setInterval(60)
+	saved := *jitter
+	defer func() { *jitter = saved }()
+
+	*jitter = 0
+	a.scheduler.mu.Lock()
+	delay := a.scheduler.delay()
+	a.scheduler.mu.Unlock()
+	if delay != time.Hour {
+		t.Errorf("the delay without jitter is %v, want an hour", delay)
+	}
+
+	*jitter = 10
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 1000; i++ {
+		a.scheduler.mu.Lock()
+		delay := a.scheduler.delay()
+		a.scheduler.mu.Unlock()
+		if delay < 54*time.Minute || delay > 66*time.Minute {
+			t.Fatalf("the delay %v is more than 10%% off an hour", delay)
+		}
+		seen[delay] = true
+	}
+	if len(seen) < 100 {
+		t.Errorf("only %d different delays in 1000", len(seen))
+	}
+}
+
+func TestSchedulerPresets(t *testing.T) {
+	a, fake, clock := newSchedulerAccount(t)
+	start := clock.Now()
+	a.scheduler.step(start)
+
+	// A new cycle loads its first preset at once, then one per interval
+	a.startPresetCycle([]string{"one", "two"})
+	a.scheduler.step(start.Add(time.Minute))
+	if fake.Slots["1"] != "1001" {
+		t.Errorf("slot 1 shows %q, want 1001 of the first preset", fake.Slots["1"])
+	}
+	a.scheduler.step(start.Add(11 * time.Minute))
+	if fake.Slots["1"] != "1002" {
+		t.Errorf("slot 1 shows %q, want 1002 of the second preset", fake.Slots["1"])
+	}
+
+	// A failed run is retried with the same preset
+	fake.Failures["FetchMicrobadges"] = errors.New("unavailable")
+	a.scheduler.step(start.Add(21 * time.Minute))
+	delete(fake.Failures, "FetchMicrobadges")
+	a.scheduler.step(a.scheduler.nextRandomization())
+	if fake.Slots["1"] != "1001" {
+		t.Errorf("slot 1 shows %q after the retry, want 1001", fake.Slots["1"])
+	}
+
+	// The schedule wins over the cycle from 09:00 to 10:00
+	if err := a.updateSchedule(schedule{{Preset: "three", Start: "09:00", End: "10:00"}}); err != nil {
+		t.Fatal(err)
+	}
+	next := a.scheduler.step(start.Add(25 * time.Minute))
+	if want := start.Add(30 * time.Minute); next.After(want) {
+		t.Errorf("step wants to wake at %v, after the schedule starts at %v", next, want)
+	}
+	a.scheduler.step(start.Add(30 * time.Minute))
+	if fake.Slots["1"] != "3001" || a.state.scheduledPresetName() != "three" {
+		t.Errorf("slot 1 shows %q with %q scheduled, want 3001 of three", fake.Slots["1"], a.state.scheduledPresetName())
+	}
+	a.scheduler.step(start.Add(45 * time.Minute))
+	if fake.Slots["1"] != "3001" {
+		t.Errorf("the cycle replaced the scheduled preset with %q", fake.Slots["1"])
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	a, fake, clock := newSchedulerAccount(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan bool)
+	go func() {
+		a.scheduler.run(ctx)
+		close(done)
+	}()
+	waitUntil := func(what string, condition func() bool) {
+		t.Helper()
+		for i := 0; i < 500 && !condition(); i++ {
+			time.Sleep(2 * time.Millisecond)
+		}
+		if !condition() {
+			t.Fatal("timed out waiting until " + what)
+		}
+	}
+
+	waitUntil("the first rotation", func() bool { return rotations(fake) == 1 && clock.waiting() == 1 })
+	for minute := 1; minute <= 10; minute++ {
+		clock.advance(time.Minute)
+		waitUntil("the loop sleeps again", func() bool { return clock.waiting() == 1 })
+	}
+	if rotations(fake) != 2 {
+		t.Errorf("rotated %d times in 10 minutes, want twice", rotations(fake))
+	}
+
+	for minute := 1; minute <= 3; minute++ {
+		clock.advance(time.Minute)
+		waitUntil("the loop sleeps again", func() bool { return clock.waiting() == 1 })
+	}
+	// Changing the interval wakes the loop without the clock moving
+	a.scheduler.setInterval(2)
+	waitUntil("the shorter interval applies", func() bool { return rotations(fake) == 3 })
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not stop when its context was cancelled")
+	}
+}
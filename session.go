@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// States of the BoardGameGeek login.
+const (
+	sessionLoggedOut        = "logged out"
+	sessionLoggedIn         = "logged in"
+	sessionExpired          = "expired"
+	sessionReauthenticating = "re-authenticating"
+	sessionFailed           = "failed"
+)
+
+// Logging in again after the session expired is tried reloginAttempts
+// times, waiting reloginBackoff after the first failure and twice as long
+// after each further one.
+const (
+	reloginAttempts = 5
+	reloginBackoff  = 2 * time.Second
+)
+
+// sessionStatus is the state of the BoardGameGeek login shown in the web
+// interface and the API.
+type sessionStatus struct {
+	State    string
+	Username string
+	Since    time.Time
+	Detail   string `json:",omitempty"`
+}
+
+// sessionCookie is a cookie of the BoardGameGeek login, saved so that the
+// login survives a restart.
+type sessionCookie struct {
+	Name  string
+	Value string
+}
+
+// cookieClient is a bggClient whose login can be saved and restored.
+type cookieClient interface {
+	Cookies() []sessionCookie
+	RestoreCookies(cookies []sessionCookie) error
+}
+
+// sessionChecker is a bggClient that can check whether its login is still
+// accepted without changing anything.
+type sessionChecker interface {
+	CheckSession() error
+}
+
+// reloginBGG wraps the bggClient of an account. When a slot update or
+// fetching the microbadge page finds the session expired it logs in again
+// and retries the request once.
+type reloginBGG struct {
+	bggClient
+	account *account
+}
+
+func (r reloginBGG) Login(username, password string) error {
+	err := r.bggClient.Login(username, password)
+	if err != nil {
+		r.account.state.setSession(sessionFailed, username, err.Error())
+	} else {
+		r.account.state.setSession(sessionLoggedIn, username, "")
+	}
+	return err
+}
+
+func (r reloginBGG) FetchMicrobadges(username string) (io.ReadCloser, error) {
+	var page io.ReadCloser
+	err := r.retry(func() error {
+		var err error
+		page, err = r.bggClient.FetchMicrobadges(username)
+		return err
+	})
+	return page, err
+}
+
+func (r reloginBGG) SetSlot(slotNumber, badgeID string) error {
+	return r.retry(func() error {
+		return r.bggClient.SetSlot(slotNumber, badgeID)
+	})
+}
+
+func (r reloginBGG) ClearSlot(slotNumber string) error {
+	return r.retry(func() error {
+		return r.bggClient.ClearSlot(slotNumber)
+	})
+}
+
+func (r reloginBGG) retry(request func() error) error {
+	failedAt := time.Now()
+	err := request()
+	if err != errSessionExpired {
+		return err
+	}
+	if reloginErr := r.relogin(failedAt); reloginErr != nil {
+		return err
+	}
+	return request()
+}
+
+// AssignedSlots returns nil when the wrapped client cannot read the slots.
+func (r reloginBGG) AssignedSlots(username string) (map[string]string, error) {
+	if client, ok := r.bggClient.(slotReader); ok {
+		return client.AssignedSlots(username)
+	}
+	return nil, nil
+}
+
+// CheckSession returns nil when the wrapped client cannot check its login.
+func (r reloginBGG) CheckSession() error {
+	if client, ok := r.bggClient.(sessionChecker); ok {
+		return client.CheckSession()
+	}
+	return nil
+}
+
+func (r reloginBGG) Cookies() []sessionCookie {
+	if client, ok := r.bggClient.(cookieClient); ok {
+		return client.Cookies()
+	}
+	return nil
+}
+
+func (r reloginBGG) RestoreCookies(cookies []sessionCookie) error {
+	if client, ok := r.bggClient.(cookieClient); ok {
+		return client.RestoreCookies(cookies)
+	}
+	return nil
+}
+
+// relogin logs in again with credentials read again from the source of the
+// last login, waiting longer after each failed attempt. A request that
+// failed before another one logged in again just uses the new login.
+func (r reloginBGG) relogin(failedAt time.Time) error {
+	a := r.account
+	a.reloginMu.Lock()
+	defer a.reloginMu.Unlock()
+	if current := a.state.sessionSnapshot(); current.State == sessionLoggedIn && current.Since.After(failedAt) {
+		return nil
+	}
+	username := a.state.Username()
+	source := a.currentLoginSource()
+	if source == nil {
+		a.state.setSession(sessionExpired, username, "Log in again to continue")
+		a.state.notify(errSessionExpired.Error() + ". Log in again to continue")
+		return errSessionExpired
+	}
+	login, err := source.Credentials()
+	if err == nil && login.Username != username {
+		err = errors.New("they are for " + login.Username + ", not " + username)
+	}
+	if err != nil {
+		a.state.setSession(sessionFailed, username, "Reading the credentials from "+source.Name()+" failed")
+		a.state.notify("Reading the credentials from " + source.Name() + " failed: " + err.Error())
+		return err
+	}
+
+	delay := reloginBackoff
+	for attempt := 1; ; attempt++ {
+		a.state.setSession(sessionReauthenticating, username, fmt.Sprintf("attempt %d of %d", attempt, reloginAttempts))
+		err := r.bggClient.Login(login.Username, login.Password)
+		if err == nil {
+			a.state.setSession(sessionLoggedIn, username, "")
+			a.state.notify(errSessionExpired.Error() + ". Logged in again as " + username)
+			a.rememberLogin()
+			return nil
+		}
+		// Retrying cannot fix a password that is no longer accepted
+		if attempt == reloginAttempts || errors.Is(err, errLoginRejected) {
+			a.state.setSession(sessionFailed, username, err.Error())
+			a.state.notify("Logging in again failed: " + err.Error())
+			return err
+		}
+		select {
+		case <-time.After(delay):
+		case <-appContext.Done():
+			a.state.setSession(sessionFailed, username, "shutting down")
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// savedCookies returns the cookies of the current login, if the client can
+// save them.
+func (a *account) savedCookies() []sessionCookie {
+	if client, ok := a.bgg.(cookieClient); ok {
+		return client.Cookies()
+	}
+	return nil
+}
+
+// restoreSession reuses the login saved by an earlier run, unless it belongs
+// to another user than the one given for the account or BoardGameGeek no
+// longer accepts it. It reports whether the login was restored. A login
+// that cannot be checked is used anyway; if it has expired, the first slot
+// update logs in again.
+func (a *account) restoreSession() bool {
+	saved, err := a.store.LoadSession()
+	if err != nil {
+		if err != errNotFound {
+			a.state.notify("Error loading the saved session: " + err.Error())
+		}
+		return false
+	}
+	if username := a.state.Username(); len(saved.Cookies) == 0 || (username != "" && username != saved.Username) {
+		return false
+	}
+	client, ok := a.bgg.(cookieClient)
+	if !ok {
+		return false
+	}
+	if err := client.RestoreCookies(saved.Cookies); err != nil {
+		a.state.notify("Error restoring the saved session: " + err.Error())
+		return false
+	}
+	if checker, ok := a.bgg.(sessionChecker); ok {
+		err := checker.CheckSession()
+		if err == errSessionExpired {
+			a.state.notify("The saved session of " + saved.Username + " has expired")
+			return false
+		}
+		if err != nil {
+			a.state.notify("Checking the saved session failed, using it anyway: " + err.Error())
+		}
+	}
+	a.state.setUsername(saved.Username)
+	a.state.setSession(sessionLoggedIn, saved.Username, "restored the login of "+saved.LastLogin.Format("2006-01-02 15:04"))
+	return true
+}
@@ -2,12 +2,21 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"sort"
 )
 
+// defaultSlotCount is the number of microbadge slots a BoardGameGeek profile
+// shows.
+const defaultSlotCount = 5
+
 type slot struct {
-	Id              string
-	AssignedBadge   string
+	Id            string
+	AssignedBadge string
+	// assigned is set once microBadger has assigned the slot, so
+	// AssignedBadge is what the profile shows
+	assigned        bool
+	Strategy        string
 	AvailableBadges map[string]*microBadge
 }
 
@@ -16,8 +25,53 @@ type microBadge struct {
 	Name        string
 	Description string
 	ImgURL      string
+	TileImgURL  string
+	PageURL     string
 	Category    string
 	Selected    []bool
+	Weights     []float64
+	// Removed is set when the badge no longer appears on the user's
+	// profile. Its selections are kept in case it comes back.
+	Removed bool
+}
+
+const defaultWeight = 1.0
+
+// validWeight reports whether weight can be given to a badge. Weights must
+// not be negative, and NaN and infinities cannot be saved.
+func validWeight(weight float64) bool {
+	return weight >= 0 && !math.IsInf(weight, 0) && !math.IsNaN(weight)
+}
+
+// Weight returns the badge's weight in the given zero-based slot. Badges
+// loaded from files written before weights existed weigh defaultWeight.
+func (mb *microBadge) Weight(slotIndex int) float64 {
+	if slotIndex < 0 || slotIndex >= len(mb.Weights) {
+		return defaultWeight
+	}
+	return mb.Weights[slotIndex]
+}
+
+func (mb *microBadge) SetWeight(slotIndex int, weight float64) {
+	for len(mb.Weights) <= slotIndex {
+		mb.Weights = append(mb.Weights, defaultWeight)
+	}
+	mb.Weights[slotIndex] = weight
+}
+
+// IsSelected reports whether the badge is selected for the given zero-based
+// slot.
+func (mb *microBadge) IsSelected(slotIndex int) bool {
+	return slotIndex >= 0 && slotIndex < len(mb.Selected) && mb.Selected[slotIndex]
+}
+
+// SetSelected grows Selected as needed, so badges saved with fewer slots
+// than are configured can be selected for the new ones.
+func (mb *microBadge) SetSelected(slotIndex int, selected bool) {
+	for len(mb.Selected) <= slotIndex {
+		mb.Selected = append(mb.Selected, false)
+	}
+	mb.Selected[slotIndex] = selected
 }
 
 func (mb *microBadge) UpdateMB(newMB *microBadge) {
@@ -25,11 +79,31 @@ func (mb *microBadge) UpdateMB(newMB *microBadge) {
 	mb.Name = newMB.Name
 	mb.Description = newMB.Description
 	mb.ImgURL = newMB.ImgURL
+	mb.TileImgURL = newMB.TileImgURL
+	mb.PageURL = newMB.PageURL
 	mb.Category = newMB.Category
 }
 
+// sameMetadata reports whether the scraped details of both badges match.
+func (mb *microBadge) sameMetadata(other *microBadge) bool {
+	return mb.Name == other.Name &&
+		mb.Description == other.Description &&
+		mb.ImgURL == other.ImgURL &&
+		mb.TileImgURL == other.TileImgURL &&
+		mb.PageURL == other.PageURL &&
+		mb.Category == other.Category
+}
+
+// clone returns a copy of the badge that shares no slices with it.
+func (mb *microBadge) clone() *microBadge {
+	c := *mb
+	c.Selected = append([]bool(nil), mb.Selected...)
+	c.Weights = append([]float64(nil), mb.Weights...)
+	return &c
+}
+
 func (mb *microBadge) String() string {
-	return fmt.Sprintf("[Id: %s, Name: %s,Description: %s, ImgURL: %s, Category: %s]", mb.Id, mb.Name, mb.Description, mb.ImgURL, mb.Category)
+	return fmt.Sprintf("[Id: %s, Name: %s,Description: %s, ImgURL: %s, TileImgURL: %s, PageURL: %s, Category: %s]", mb.Id, mb.Name, mb.Description, mb.ImgURL, mb.TileImgURL, mb.PageURL, mb.Category)
 }
 
 func (mb *microBadge) SetImg(img string) {
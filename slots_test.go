@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestValidWeight(t *testing.T) {
+	for _, test := range []struct {
+		weight float64
+		valid  bool
+	}{
+		{0, true},
+		{1, true},
+		{2.5, true},
+		{-1, false},
+		{math.Inf(1), false},
+		{math.Inf(-1), false},
+		{math.NaN(), false},
+	} {
+		if got := validWeight(test.weight); got != test.valid {
+			t.Errorf("validWeight(%v) = %v, want %v", test.weight, got, test.valid)
+		}
+	}
+}
+
+func TestSlotSubmitIgnoresInvalidWeights(t *testing.T) {
+	a := newTestAccount(t, newFakeBGG(nil))
+	a.state.replaceBadges(map[string]*microBadge{
+		"1": {Id: "1"}, "2": {Id: "2"}, "3": {Id: "3"}, "4": {Id: "4"},
+	})
+	form := url.Values{
+		"slot1":     {"1", "2", "3", "4"},
+		"weight1-1": {"Inf"},
+		"weight1-2": {"NaN"},
+		"weight1-3": {"-1"},
+		"weight1-4": {"2.5"},
+	}
+	r := httptest.NewRequest("POST", "/slotSubmit", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	slotSubmitHandler(w, r)
+	if w.Code != 200 {
+		t.Fatalf("got status %d: %s", w.Code, w.Body.String())
+	}
+
+	badges := a.state.badgesSnapshot()
+	for _, id := range []string{"1", "2", "3"} {
+		if weight := badges[id].Weight(0); weight != defaultWeight {
+			t.Errorf("badge %s weighs %v, want the default", id, weight)
+		}
+	}
+	if weight := badges["4"].Weight(0); weight != 2.5 {
+		t.Errorf("badge 4 weighs %v, want 2.5", weight)
+	}
+	saved, err := a.store.LoadSelections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Badges) != 4 {
+		t.Errorf("saved %d badges, want 4", len(saved.Badges))
+	}
+}
@@ -0,0 +1,467 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// appState owns everything the web handlers and the scheduler of one account
+// share. Every read and write goes through its methods, which hold mu, and
+// reads hand out copies so callers never touch a badge another goroutine may
+// be changing. Notifications have their own lock so they can be sent from
+// anywhere, including while mu is held.
+type appState struct {
+	// account names the account in the log, and never changes
+	account string
+
+	mu            sync.RWMutex
+	username      string
+	slots         map[string]*slot
+	badges        map[string]*microBadge
+	categories    map[string]mbSlice
+	history       rotationHistory
+	interval      int
+	slotCount     int
+	activePresets []string
+
+	schedule schedule
+	// scheduledPreset is the preset of the active schedule entry, if any
+	scheduledPreset string
+
+	session sessionStatus
+
+	notifyMu      sync.Mutex
+	notifications notification
+}
+
+type notification []string
+
+func newAppState(account string) *appState {
+	return &appState{
+		account:       account,
+		slots:         map[string]*slot{},
+		badges:        map[string]*microBadge{},
+		categories:    map[string]mbSlice{},
+		history:       rotationHistory{},
+		interval:      1,
+		slotCount:     defaultSlotCount,
+		session:       sessionStatus{State: sessionLoggedOut, Since: time.Now()},
+		notifications: make(notification, 0),
+	}
+}
+
+func (s *appState) notify(message string) {
+	message = redactSecrets(message)
+	if *headless {
+		s.log(message)
+	}
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	if len(s.notifications) > 50 {
+		s.notifications = s.notifications[:len(s.notifications)-1]
+	}
+	currentTime := time.Now().Format("2006-01-02 15:04:05 ")
+	s.notifications = append(notification{currentTime + ": " + message}, s.notifications...)
+}
+
+// log writes message to the log, naming the account unless it is the
+// default one.
+func (s *appState) log(message string) {
+	if s.account != defaultAccount {
+		message = "[" + s.account + "] " + message
+	}
+	log.Println(message)
+}
+
+func (s *appState) notificationList() notification {
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	return append(notification{}, s.notifications...)
+}
+
+// Interval returns the number of minutes between randomizations.
+func (s *appState) Interval() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.interval
+}
+
+func (s *appState) setInterval(minutes int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interval = minutes
+}
+
+// SlotCount returns the number of microbadge slots being rotated.
+func (s *appState) SlotCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.slotCount
+}
+
+func (s *appState) setSlotCount(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.slotCount = count
+}
+
+// slotIDs returns the Id of every slot, "1" to the slot count.
+func (s *appState) slotIDs() []string {
+	count := s.SlotCount()
+	slotIDs := make([]string, count)
+	for i := range slotIDs {
+		slotIDs[i] = fmt.Sprintf("%d", i+1)
+	}
+	return slotIDs
+}
+
+func (s *appState) setActivePresets(presets []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activePresets = append([]string{}, presets...)
+}
+
+// activePresetList returns the presets being cycled through, if any.
+func (s *appState) activePresetList() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string{}, s.activePresets...)
+}
+
+func (s *appState) setSchedule(entries schedule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedule = append(schedule{}, entries...)
+}
+
+// scheduleSnapshot returns a copy of the schedule entries.
+func (s *appState) scheduleSnapshot() schedule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := make(schedule, len(s.schedule))
+	for i, e := range s.schedule {
+		e.Weekdays = append([]string(nil), e.Weekdays...)
+		entries[i] = e
+	}
+	return entries
+}
+
+func (s *appState) setScheduledPreset(preset string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scheduledPreset = preset
+}
+
+// scheduledPresetName returns the preset of the active schedule entry, or ""
+// when no entry is active.
+func (s *appState) scheduledPresetName() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.scheduledPreset
+}
+
+func (s *appState) setSession(sessionState, username, detail string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session = sessionStatus{State: sessionState, Username: username, Since: time.Now(), Detail: detail}
+}
+
+func (s *appState) sessionSnapshot() sessionStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.session
+}
+
+// Username returns the BoardGameGeek user of the account.
+func (s *appState) Username() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.username
+}
+
+func (s *appState) setUsername(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.username = username
+}
+
+// replaceBadges swaps in a badge set loaded from a file.
+func (s *appState) replaceBadges(badges map[string]*microBadge) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.badges = badges
+	s.rebuildCategories()
+}
+
+// mergeScraped folds a fresh scrape into the badge set and returns what
+// changed. See mergeMicroBadges for partial.
+func (s *appState) mergeScraped(scraped []*microBadge, partial bool) syncDiff {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	diff := mergeMicroBadges(s.badges, scraped, partial)
+	s.rebuildCategories()
+	return diff
+}
+
+func (s *appState) badgeCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.badges)
+}
+
+// badgesSnapshot returns a copy of every badge keyed by Id.
+func (s *appState) badgesSnapshot() map[string]*microBadge {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	badges := make(map[string]*microBadge, len(s.badges))
+	for id, mb := range s.badges {
+		badges[id] = mb.clone()
+	}
+	return badges
+}
+
+// categoriesSnapshot returns a copy of the badges grouped by category, each
+// group sorted for display.
+func (s *appState) categoriesSnapshot() map[string]mbSlice {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	categories := make(map[string]mbSlice, len(s.categories))
+	for category, badges := range s.categories {
+		copies := make(mbSlice, len(badges))
+		for i, mb := range badges {
+			copies[i] = mb.clone()
+		}
+		categories[category] = copies
+	}
+	return categories
+}
+
+func (s *appState) rebuildCategories() {
+	categories := make(map[string]mbSlice)
+	for _, v := range s.badges {
+		categories[v.Category] = append(categories[v.Category], v)
+	}
+
+	for _, v := range categories {
+		mbSort(v)
+	}
+	s.categories = categories
+}
+
+// selectedSlots returns the Ids of the badges selected for each slot, in the
+// form submitSelections expects.
+func (s *appState) selectedSlots() map[string][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	availableSlots := make(map[string][]string)
+	for _, v := range s.badges {
+
+		for i, sel := range v.Selected {
+			index := fmt.Sprintf("%d", i+1)
+			if sel {
+				availableSlots[index] = append(availableSlots[index], v.Id)
+			}
+		}
+	}
+	return availableSlots
+}
+
+// submitSelections makes the badges listed for each slot the ones it picks
+// from and returns copies of every known badge for saving, selected or not,
+// so the next sync compares against the whole catalogue. Selections for
+// slots beyond the slot count are left alone, so they survive running with
+// fewer slots for a while.
+func (s *appState) submitSelections(formSlots map[string][]string) map[string]*microBadge {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mbSelectedMap := make(map[string][]bool)
+	for i := 1; i <= s.slotCount; i++ {
+		slotID := fmt.Sprintf("%d", i)
+		currentSlot, ok := s.slots[slotID]
+		if !ok {
+			currentSlot = &slot{Id: slotID}
+			s.slots[slotID] = currentSlot
+		}
+		currentSlot.AvailableBadges = map[string]*microBadge{}
+		for _, v := range formSlots[slotID] {
+			if mb, ok := s.badges[v]; ok {
+				if mbSelected, ok := mbSelectedMap[v]; ok {
+					mbSelected[i-1] = true
+				} else {
+					mbSelectedMap[v] = make([]bool, s.slotCount)
+					mbSelectedMap[v][i-1] = true
+				}
+				if !mb.Removed {
+					currentSlot.AvailableBadges[v] = mb
+				}
+			}
+		}
+	}
+	knownMicroBadges := make(map[string]*microBadge, len(s.badges))
+
+	for key, mb := range s.badges {
+		mbSelected := mbSelectedMap[key]
+		for i := 0; i < s.slotCount; i++ {
+			mb.SetSelected(i, i < len(mbSelected) && mbSelected[i])
+		}
+		knownMicroBadges[mb.Id] = mb.clone()
+	}
+	return knownMicroBadges
+}
+
+// setWeight sets a badge's weight in the zero-based slot. It reports whether
+// the badge exists.
+func (s *appState) setWeight(slotIndex int, badgeID string, weight float64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mb, ok := s.badges[badgeID]
+	if ok {
+		mb.SetWeight(slotIndex, weight)
+	}
+	return ok
+}
+
+func (s *appState) setSlotStrategy(slotID, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if currentSlot, ok := s.slots[slotID]; ok {
+		currentSlot.Strategy = name
+	} else {
+		s.slots[slotID] = &slot{Id: slotID, Strategy: name, AvailableBadges: map[string]*microBadge{}}
+	}
+}
+
+// slotStrategies returns the strategies chosen for single slots, by slot id.
+func (s *appState) slotStrategies() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	strategies := map[string]string{}
+	for slotID, currentSlot := range s.slots {
+		if currentSlot.Strategy != "" {
+			strategies[slotID] = currentSlot.Strategy
+		}
+	}
+	return strategies
+}
+
+// slotStrategy returns the strategy the slot uses, which is the -strategy
+// flag unless one was chosen for the slot.
+func (s *appState) slotStrategy(slotID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if currentSlot, ok := s.slots[slotID]; ok && currentSlot.Strategy != "" {
+		return currentSlot.Strategy
+	}
+	return *strategy
+}
+
+// pickBadges chooses the next badge for every slot using each slot's
+// strategy from strategies. A badge is never picked for two slots, and
+// recently shown badges are skipped unless nothing else is left. The pick
+// for slot i+1 is at index i. Slots with nothing to pick, including slots
+// that were never set up, get a badge with an empty Id, which clears them.
+func (s *appState) pickBadges(strategies map[string]selectionStrategy) []microBadge {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	badgeList := []microBadge{}
+	usedBadges := map[string]bool{}
+	for i := 1; i <= s.slotCount; i++ {
+		slotID := fmt.Sprintf("%d", i)
+		currentSlot, ok := s.slots[slotID]
+		if !ok {
+			badgeList = append(badgeList, microBadge{Id: ""})
+			continue
+		}
+		slotStrategy := currentSlot.Strategy
+		if slotStrategy == "" {
+			slotStrategy = *strategy
+		}
+		candidates := sortedCandidates(currentSlot.AvailableBadges, usedBadges)
+		// Only fall back to a repeat when every candidate was shown recently
+		if fresh := withoutBadges(candidates, s.history.recentlyShown(slotID, *noRepeat)); len(fresh) > 0 {
+			candidates = fresh
+		}
+		if mb := getStrategy(strategies, slotStrategy).Pick(slotID, candidates); mb != nil {
+			usedBadges[mb.Id] = true
+			badgeList = append(badgeList, *mb.clone())
+		} else {
+			badgeList = append(badgeList, microBadge{Id: ""})
+		}
+	}
+	return badgeList
+}
+
+// slotsSnapshot returns a copy of every slot without its available badges.
+func (s *appState) slotsSnapshot() map[string]slot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	slots := make(map[string]slot, len(s.slots))
+	for slotID, currentSlot := range s.slots {
+		slots[slotID] = slot{Id: currentSlot.Id, AssignedBadge: currentSlot.AssignedBadge, Strategy: currentSlot.Strategy}
+	}
+	return slots
+}
+
+func (s *appState) setAssignedBadge(slotID, badgeID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if givenSlot, ok := s.slots[slotID]; ok {
+		givenSlot.AssignedBadge = badgeID
+		givenSlot.assigned = true
+	} else {
+		s.slots[slotID] = &slot{Id: slotID, AssignedBadge: badgeID, assigned: true}
+	}
+}
+
+// assignedBadgeID returns the id of the badge microBadger last assigned to
+// the slot, and false when it has not assigned the slot yet. Assignments of
+// earlier runs come from the rotation history, which records every one.
+func (s *appState) assignedBadgeID(slotID string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if currentSlot, ok := s.slots[slotID]; ok && currentSlot.assigned {
+		return currentSlot.AssignedBadge, true
+	}
+	if entries := s.history[slotID]; len(entries) > 0 {
+		return entries[0].BadgeId, true
+	}
+	return "", false
+}
+
+// assignedBadge returns a copy of the badge the slot currently shows.
+func (s *appState) assignedBadge(slotID string) (microBadge, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if currentSlot, ok := s.slots[slotID]; ok {
+		if mb, ok := s.badges[currentSlot.AssignedBadge]; ok {
+			return *mb.clone(), true
+		}
+	}
+	return microBadge{}, false
+}
+
+func (s *appState) recordHistory(slotID, badgeID string, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history.record(slotID, badgeID, t)
+}
+
+func (s *appState) setHistory(history rotationHistory) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = history
+}
+
+// historySnapshot returns a copy of the rotation history.
+func (s *appState) historySnapshot() rotationHistory {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	history := make(rotationHistory, len(s.history))
+	for slotID, entries := range s.history {
+		history[slotID] = append([]historyEntry{}, entries...)
+	}
+	return history
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPickBadgesKeepsSlotPositions(t *testing.T) {
+	s := newAppState(defaultAccount)
+	s.slots["3"] = &slot{Id: "3", AvailableBadges: map[string]*microBadge{"1": {Id: "1"}}}
+	picks := s.pickBadges(newStrategies(1))
+	if len(picks) != defaultSlotCount {
+		t.Fatalf("got %d picks, want one for each of the %d slots", len(picks), defaultSlotCount)
+	}
+	for i, mb := range picks {
+		want := ""
+		if i == 2 {
+			want = "1"
+		}
+		if mb.Id != want {
+			t.Errorf("slot %d got badge %q, want %q", i+1, mb.Id, want)
+		}
+	}
+}
+
+func TestPickBadgesAvoidsRecentRepeats(t *testing.T) {
+	s := newAppState(defaultAccount)
+	s.setSlotCount(1)
+	s.submitSelections(map[string][]string{})
+	s.slots["1"].AvailableBadges = map[string]*microBadge{"1": {Id: "1"}, "2": {Id: "2"}}
+	strategies := newStrategies(1)
+	for i := 0; i < 20; i++ {
+		picked := s.pickBadges(strategies)[0].Id
+		if i > 0 {
+			if last := s.history["1"][0].BadgeId; picked == last {
+				t.Fatalf("badge %s was picked twice in a row", picked)
+			}
+		}
+		s.recordHistory("1", picked, time.Now())
+	}
+
+	// A repeat is allowed when nothing else is left
+	s.slots["1"].AvailableBadges = map[string]*microBadge{"1": {Id: "1"}}
+	s.recordHistory("1", "1", time.Now())
+	if picked := s.pickBadges(strategies)[0].Id; picked != "1" {
+		t.Errorf("got badge %q, want the only badge 1", picked)
+	}
+}
+
+// TestConcurrentHandlers runs randomizations, syncs, slot submits, API
+// requests and page renders at the same time. Run it with -race.
+func TestConcurrentHandlers(t *testing.T) {
+	page, err := ioutil.ReadFile("testdata/microbadges/profile.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestAccount(t, newFakeBGG(page))
+	if err := a.loginWith(staticCredentials{name: "the test", login: credentials{"user", "pw"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.getMicroBadges(); err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{}
+	for id := range a.state.badgesSnapshot() {
+		ids = append(ids, id)
+	}
+	form := url.Values{"slot1": ids, "slot2": ids, "weight1-" + ids[0]: {"3"}, "strategy1": {"round-robin"}}
+
+	var wg sync.WaitGroup
+	run := func(f func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		run(func() { a.randomizeBadges() })
+		run(func() { a.getMicroBadges() })
+		run(func() {
+			r := httptest.NewRequest("POST", "/slotSubmit", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			slotSubmitHandler(httptest.NewRecorder(), r)
+		})
+		run(func() {
+			for _, path := range []string{"/api/v1/badges", "/api/v1/categories", "/api/v1/slots", "/api/v1/slots/1", "/api/v1/interval", "/api/v1/scheduler", "/api/v1/session"} {
+				if code, body := apiRequest("GET", path, ""); code != 200 {
+					t.Errorf("GET %s: %d %s", path, code, body)
+				}
+			}
+		})
+		run(func() {
+			w := httptest.NewRecorder()
+			rootHandler(w, httptest.NewRequest("GET", "/", nil))
+			if body := w.Body.String(); strings.Contains(body, "error: ") || !strings.Contains(body, "</html>") {
+				t.Errorf("rendering the page failed: %s", body)
+			}
+			historyHandler(httptest.NewRecorder(), httptest.NewRequest("GET", "/history", nil))
+			slotHandler(httptest.NewRecorder(), httptest.NewRequest("GET", "/slot/1", nil))
+			notificationHandler(httptest.NewRecorder(), httptest.NewRequest("GET", "/notification", nil))
+		})
+		run(func() {
+			r := httptest.NewRequest("POST", "/setInterval", strings.NewReader("interval=3"))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			setIntervalHandler(httptest.NewRecorder(), r)
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errNotFound is returned by a storage when the requested data was never
+// saved.
+var errNotFound = errors.New("not found")
+
+// storage persists everything microBadger keeps between runs. Selections
+// and presets are handed over as mbFiles, built with newMBFile, so every
+// backend stores the same versioned format. Loading something that was
+// never saved returns errNotFound.
+type storage interface {
+	LoadSelections() (*mbFile, error)
+	SaveSelections(f *mbFile) error
+
+	Presets() ([]string, error)
+	LoadPreset(name string) (*mbFile, error)
+	SavePreset(name string, f *mbFile) error
+	// RenamePreset returns errPresetExists when newName is taken.
+	RenamePreset(name, newName string) error
+	DeletePreset(name string) error
+
+	LoadHistory() (rotationHistory, error)
+	SaveHistory(history rotationHistory) error
+
+	LoadSchedule() (schedule, error)
+	SaveSchedule(entries schedule) error
+
+	LoadSession() (sessionInfo, error)
+	SaveSession(session sessionInfo) error
+
+	Close() error
+}
+
+// sessionInfo is what is remembered about the last BoardGameGeek login. The
+// cookies let the next run reuse the login. Passwords are never stored here.
+type sessionInfo struct {
+	Username  string
+	LastLogin time.Time
+	Cookies   []sessionCookie `json:",omitempty"`
+}
+
+// rememberLogin records a successful login.
+func (a *account) rememberLogin() {
+	err := a.store.SaveSession(sessionInfo{Username: a.state.Username(), LastLogin: time.Now(), Cookies: a.savedCookies()})
+	if err != nil {
+		a.state.notify("Error saving session details: " + err.Error())
+	}
+}
+
+// storageNames lists the values of the -storage flag.
+var storageNames = []string{"files", "bolt"}
+
+// openStorage opens the named backend in dir, relative to appDir. notify
+// receives the messages of the file storage about recovered and upgraded
+// files.
+func openStorage(name, dir string, notify func(message string)) (storage, error) {
+	switch name {
+	case "files":
+		return &fileStore{dir: dir, notify: notify}, nil
+	case "bolt":
+		return openBoltStore(filepath.Join(appDir, dir, boltFile))
+	}
+	return nil, fmt.Errorf("unknown storage %q, use one of %s", name, strings.Join(storageNames, ", "))
+}
+
+const (
+	selectedFile = "selected.mb"
+	sessionFile  = "session.mb"
+)
+
+// fileStore keeps each kind of data in its own JSON file in dir, relative to
+// appDir: selected.mb, preset-<name>.mb, history.mb, schedule.mb and
+// session.mb.
+type fileStore struct {
+	dir    string
+	notify func(message string)
+
+	// mu keeps a load from reading a file another goroutine is replacing
+	// or upgrading, and two saves from rotating the same generations.
+	mu sync.Mutex
+}
+
+func presetFileName(name string) string {
+	return "preset-" + name + ".mb"
+}
+
+// file returns the name of one of the store's files relative to appDir.
+func (s *fileStore) file(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+// presetFile returns the file of a preset, refusing names that could point
+// outside the store's directory.
+func (s *fileStore) presetFile(name string) (string, error) {
+	if !storedPresetName(name) {
+		return "", errInvalidPresetName
+	}
+	return s.file(presetFileName(name)), nil
+}
+
+// loadMB reads a .mb file and, when it was written in an older format,
+// rewrites it in the current one.
+func (s *fileStore) loadMB(file string) (*mbFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := readMBFile(file, s.notify)
+	if err != nil {
+		return nil, err
+	}
+	if f.upgradedFrom < mbFileVersion {
+		if upgradeErr := upgradeMBFile(file, f, s.notify); upgradeErr != nil {
+			s.notify("Error upgrading " + file + ": " + upgradeErr.Error())
+		}
+	}
+	return f, nil
+}
+
+func (s *fileStore) save(file string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeMapToFile(file, v)
+}
+
+func (s *fileStore) LoadSelections() (*mbFile, error) {
+	return s.loadMB(s.file(selectedFile))
+}
+
+func (s *fileStore) SaveSelections(f *mbFile) error {
+	return s.save(s.file(selectedFile), f)
+}
+
+func (s *fileStore) Presets() ([]string, error) {
+	fileList, err := ioutil.ReadDir(filepath.Join(appDir, s.dir))
+	if err != nil {
+		return nil, err
+	}
+	presetList := make([]string, 0)
+	for _, file := range fileList {
+		if strings.HasPrefix(file.Name(), "preset-") && strings.HasSuffix(file.Name(), ".mb") {
+			presetList = append(presetList, strings.TrimPrefix(strings.TrimSuffix(file.Name(), ".mb"), "preset-"))
+		}
+	}
+	return presetList, nil
+}
+
+func (s *fileStore) LoadPreset(name string) (*mbFile, error) {
+	file, err := s.presetFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadMB(file)
+}
+
+func (s *fileStore) SavePreset(name string, f *mbFile) error {
+	file, err := s.presetFile(name)
+	if err != nil {
+		return err
+	}
+	return s.save(file, f)
+}
+
+func (s *fileStore) RenamePreset(name, newName string) error {
+	file, err := s.presetFile(name)
+	if err != nil {
+		return err
+	}
+	newFile, err := s.presetFile(newName)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := os.Stat(filepath.Join(appDir, newFile)); err == nil {
+		return errPresetExists
+	}
+	err = os.Rename(filepath.Join(appDir, file), filepath.Join(appDir, newFile))
+	if os.IsNotExist(err) {
+		return errNotFound
+	}
+	return err
+}
+
+func (s *fileStore) DeletePreset(name string) error {
+	file, err := s.presetFile(name)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err = os.Remove(filepath.Join(appDir, file))
+	if os.IsNotExist(err) {
+		return errNotFound
+	}
+	return err
+}
+
+func (s *fileStore) LoadHistory() (rotationHistory, error) {
+	history := rotationHistory{}
+	err := s.loadJSON(s.file(historyFile), func(data []byte) error {
+		history = rotationHistory{}
+		return json.Unmarshal(data, &history)
+	})
+	return history, err
+}
+
+func (s *fileStore) SaveHistory(history rotationHistory) error {
+	return s.save(s.file(historyFile), history)
+}
+
+func (s *fileStore) LoadSchedule() (schedule, error) {
+	entries := schedule{}
+	err := s.loadJSON(s.file(scheduleFile), func(data []byte) error {
+		entries = schedule{}
+		return json.Unmarshal(data, &entries)
+	})
+	return entries, err
+}
+
+func (s *fileStore) SaveSchedule(entries schedule) error {
+	return s.save(s.file(scheduleFile), entries)
+}
+
+func (s *fileStore) LoadSession() (sessionInfo, error) {
+	var session sessionInfo
+	err := s.loadJSON(s.file(sessionFile), func(data []byte) error {
+		session = sessionInfo{}
+		return json.Unmarshal(data, &session)
+	})
+	return session, err
+}
+
+func (s *fileStore) SaveSession(session sessionInfo) error {
+	return s.save(s.file(sessionFile), session)
+}
+
+// loadJSON decodes file, falling back to its earlier generations, and maps a
+// missing file to errNotFound.
+func (s *fileStore) loadJSON(file string, decode func(data []byte) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := readGenerations(file, decode, s.notify)
+	if os.IsNotExist(err) {
+		return errNotFound
+	}
+	if _, ok := err.(*os.PathError); ok {
+		return errors.New("Error reading " + file + ": " + err.Error())
+	}
+	if err != nil {
+		return errors.New("Error in file format of " + file + ": " + err.Error())
+	}
+	return nil
+}
+
+func (s *fileStore) Close() error {
+	return nil
+}
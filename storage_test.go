@@ -0,0 +1,213 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// forEachStorage runs test once with every storage backend as -storage.
+func forEachStorage(t *testing.T, test func(t *testing.T)) {
+	for _, name := range storageNames {
+		t.Run(name, func(t *testing.T) {
+			saved := *storageName
+			*storageName = name
+			t.Cleanup(func() { *storageName = saved })
+			test(t)
+		})
+	}
+}
+
+// openTestStorage opens the -storage backend in a fresh appDir.
+func openTestStorage(t *testing.T) storage {
+	t.Helper()
+	appDir = t.TempDir()
+	store, err := openStorage(*storageName, "", func(message string) { t.Log(message) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// storageContents is one of everything a storage keeps.
+type storageContents struct {
+	selections *mbFile
+	preset     *mbFile
+	history    rotationHistory
+	schedule   schedule
+	session    sessionInfo
+}
+
+func newStorageContents() storageContents {
+	now := time.Date(2024, 5, 4, 12, 30, 0, 0, time.UTC)
+	badges := func(selected ...bool) map[string]*microBadge {
+		return map[string]*microBadge{"1001": {Id: "1001", Name: "Badge 1001", Selected: selected}}
+	}
+	return storageContents{
+		selections: &mbFile{Version: mbFileVersion, Username: "user", SlotCount: 2, Badges: badges(true, false)},
+		preset:     &mbFile{Version: mbFileVersion, Username: "user", SlotCount: 2, Badges: badges(false, true)},
+		history:    rotationHistory{"1": {{BadgeId: "1001", Time: now}}},
+		schedule:   schedule{{Preset: "weekend", Weekdays: []string{"Sat", "Sun"}}},
+		session:    sessionInfo{Username: "user", LastLogin: now, Cookies: []sessionCookie{{Name: "SessionID", Value: "abc"}}},
+	}
+}
+
+// save puts c into store, with the preset under name.
+func (c storageContents) save(t *testing.T, store storage, name string) {
+	t.Helper()
+	for what, err := range map[string]error{
+		"selections": store.SaveSelections(c.selections),
+		"preset":     store.SavePreset(name, c.preset),
+		"history":    store.SaveHistory(c.history),
+		"schedule":   store.SaveSchedule(c.schedule),
+		"session":    store.SaveSession(c.session),
+	} {
+		if err != nil {
+			t.Fatalf("saving the %s: %v", what, err)
+		}
+	}
+}
+
+// check compares what store holds with c, the preset under name.
+func (c storageContents) check(t *testing.T, store storage, name string) {
+	t.Helper()
+	selections, err := store.LoadSelections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(presetSlots(selections.Badges), presetSlots(c.selections.Badges)) || selections.Badges["1001"].Name != "Badge 1001" {
+		t.Errorf("the selections are %v", presetSlots(selections.Badges))
+	}
+	preset, err := store.LoadPreset(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(presetSlots(preset.Badges), presetSlots(c.preset.Badges)) {
+		t.Errorf("preset %s selects %v", name, presetSlots(preset.Badges))
+	}
+	history, err := store.LoadHistory()
+	if err != nil || !reflect.DeepEqual(history, c.history) {
+		t.Errorf("the history is %v, %v", history, err)
+	}
+	entries, err := store.LoadSchedule()
+	if err != nil || !reflect.DeepEqual(entries, c.schedule) {
+		t.Errorf("the schedule is %+v, %v", entries, err)
+	}
+	session, err := store.LoadSession()
+	if err != nil || !reflect.DeepEqual(session, c.session) {
+		t.Errorf("the session is %+v, %v", session, err)
+	}
+}
+
+func TestStorageIsEmpty(t *testing.T) {
+	forEachStorage(t, func(t *testing.T) {
+		store := openTestStorage(t)
+		if _, err := store.LoadSelections(); err != errNotFound {
+			t.Errorf("LoadSelections gave %v", err)
+		}
+		if _, err := store.LoadPreset("weekend"); err != errNotFound {
+			t.Errorf("LoadPreset gave %v", err)
+		}
+		if _, err := store.LoadHistory(); err != errNotFound {
+			t.Errorf("LoadHistory gave %v", err)
+		}
+		if _, err := store.LoadSchedule(); err != errNotFound {
+			t.Errorf("LoadSchedule gave %v", err)
+		}
+		if _, err := store.LoadSession(); err != errNotFound {
+			t.Errorf("LoadSession gave %v", err)
+		}
+		if presets, err := store.Presets(); err != nil || len(presets) != 0 {
+			t.Errorf("Presets gave %v, %v", presets, err)
+		}
+	})
+}
+
+func TestStorageRoundTrip(t *testing.T) {
+	forEachStorage(t, func(t *testing.T) {
+		store := openTestStorage(t)
+		contents := newStorageContents()
+		contents.save(t, store, "weekend")
+		contents.check(t, store, "weekend")
+
+		// The next run finds the same
+		if err := store.Close(); err != nil {
+			t.Fatal(err)
+		}
+		reopened, err := openStorage(*storageName, "", func(message string) { t.Log(message) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reopened.Close()
+		contents.check(t, reopened, "weekend")
+	})
+}
+
+func TestStoragePresets(t *testing.T) {
+	forEachStorage(t, func(t *testing.T) {
+		store := openTestStorage(t)
+		preset := newStorageContents().preset
+		for _, name := range []string{"weekend", "v1.2", "weekday"} {
+			if err := store.SavePreset(name, preset); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if presets, err := store.Presets(); err != nil || !reflect.DeepEqual(presets, []string{"v1.2", "weekday", "weekend"}) {
+			t.Errorf("Presets gave %v, %v", presets, err)
+		}
+		if err := store.RenamePreset("weekend", "weekday"); err != errPresetExists {
+			t.Errorf("renaming onto an existing preset gave %v", err)
+		}
+		if err := store.RenamePreset("missing", "other"); err != errNotFound {
+			t.Errorf("renaming a missing preset gave %v", err)
+		}
+		if err := store.RenamePreset("weekend", "saturday"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.LoadPreset("weekend"); err != errNotFound {
+			t.Errorf("the old name still loads: %v", err)
+		}
+		if _, err := store.LoadPreset("saturday"); err != nil {
+			t.Errorf("the new name does not load: %v", err)
+		}
+		if err := store.DeletePreset("saturday"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.DeletePreset("saturday"); err != errNotFound {
+			t.Errorf("deleting a missing preset gave %v", err)
+		}
+		if presets, err := store.Presets(); err != nil || !reflect.DeepEqual(presets, []string{"v1.2", "weekday"}) {
+			t.Errorf("Presets gave %v, %v", presets, err)
+		}
+	})
+}
+
+func TestImportCommand(t *testing.T) {
+	newCommandAccount(t)
+	files := &fileStore{dir: accountDir(defaultAccount), notify: func(message string) { t.Log(message) }}
+	contents := newStorageContents()
+	contents.save(t, files, "v1.2")
+	for _, name := range []string{"weekend", "weekday"} {
+		if err := files.SavePreset(name, contents.preset); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved := *storageName
+	*storageName = "bolt"
+	defer func() { *storageName = saved }()
+	a := reopenAccount(t, newFakeBGG(nil))
+	if code := run("import"); code != exitOK {
+		t.Fatalf("import exited with %d", code)
+	}
+	contents.check(t, a.store, "v1.2")
+	if presets, err := a.store.Presets(); err != nil || !reflect.DeepEqual(presets, []string{"v1.2", "weekday", "weekend"}) {
+		t.Errorf("bolt holds the presets %v, %v", presets, err)
+	}
+	// Importing again replaces what was imported before
+	if code := run("import"); code != exitOK {
+		t.Errorf("the second import exited with %d", code)
+	}
+	contents.check(t, a.store, "v1.2")
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+const defaultStrategy = "weighted"
+
+// selectionStrategy chooses which badge a slot shows next. Candidates are
+// always sorted by Id and never contain a badge already used by an earlier
+// slot in the same randomization, so implementations only have to pick one.
+type selectionStrategy interface {
+	Name() string
+	Pick(slotID string, candidates []*microBadge) *microBadge
+}
+
+// strategyNames lists the strategies in the order they are shown in the UI.
+var strategyNames = []string{"random", "round-robin", "least-recent", "weighted"}
+
+// newStrategies builds one instance of every strategy sharing a source seeded
+// with seed, so a fixed seed always produces the same sequence of picks.
+func newStrategies(seed int64) map[string]selectionStrategy {
+	rng := rand.New(rand.NewSource(seed))
+	return map[string]selectionStrategy{
+		"random":       &uniformStrategy{rng: rng},
+		"round-robin":  &roundRobinStrategy{last: map[string]string{}},
+		"least-recent": &leastRecentStrategy{lastShown: map[string]int{}},
+		"weighted":     &weightedStrategy{rng: rng, weight: slotWeight},
+	}
+}
+
+// getStrategy returns the named strategy of strategies, falling back to the
+// default for unknown or empty names.
+func getStrategy(strategies map[string]selectionStrategy, name string) selectionStrategy {
+	if s, ok := strategies[name]; ok {
+		return s
+	}
+	return strategies[defaultStrategy]
+}
+
+func validStrategy(name string) bool {
+	for _, v := range strategyNames {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedCandidates returns the badges of a slot sorted by Id, skipping any
+// whose Id is in used.
+func sortedCandidates(available map[string]*microBadge, used map[string]bool) []*microBadge {
+	candidates := make([]*microBadge, 0, len(available))
+	for _, mb := range available {
+		if used[mb.Id] {
+			continue
+		}
+		candidates = append(candidates, mb)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Id < candidates[j].Id
+	})
+	return candidates
+}
+
+type uniformStrategy struct {
+	rng *rand.Rand
+}
+
+func (s *uniformStrategy) Name() string {
+	return "random"
+}
+
+func (s *uniformStrategy) Pick(slotID string, candidates []*microBadge) *microBadge {
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[s.rng.Intn(len(candidates))]
+}
+
+// roundRobinStrategy walks each slot's candidates in Id order, continuing
+// after the badge it picked last time.
+type roundRobinStrategy struct {
+	last map[string]string
+}
+
+func (s *roundRobinStrategy) Name() string {
+	return "round-robin"
+}
+
+func (s *roundRobinStrategy) Pick(slotID string, candidates []*microBadge) *microBadge {
+	if len(candidates) == 0 {
+		return nil
+	}
+	picked := candidates[0]
+	for _, mb := range candidates {
+		if mb.Id > s.last[slotID] {
+			picked = mb
+			break
+		}
+	}
+	s.last[slotID] = picked.Id
+	return picked
+}
+
+// leastRecentStrategy picks the badge that has gone the longest without being
+// shown in any slot. Badges never shown win, ties go to the lowest Id.
+type leastRecentStrategy struct {
+	cycle     int
+	lastShown map[string]int
+}
+
+func (s *leastRecentStrategy) Name() string {
+	return "least-recent"
+}
+
+func (s *leastRecentStrategy) Pick(slotID string, candidates []*microBadge) *microBadge {
+	if len(candidates) == 0 {
+		return nil
+	}
+	picked := candidates[0]
+	for _, mb := range candidates[1:] {
+		if s.lastShown[mb.Id] < s.lastShown[picked.Id] {
+			picked = mb
+		}
+	}
+	s.cycle++
+	s.lastShown[picked.Id] = s.cycle
+	return picked
+}
+
+// slotWeight returns the weight the user gave mb in the slot numbered slotID.
+func slotWeight(slotID string, mb *microBadge) float64 {
+	slotNumber, err := strconv.Atoi(slotID)
+	if err != nil {
+		return defaultWeight
+	}
+	return mb.Weight(slotNumber - 1)
+}
+
+// weightedStrategy picks a badge with probability proportional to its weight.
+// Badges with a weight of zero or less are never picked unless every
+// candidate has one, in which case the pick is uniform.
+type weightedStrategy struct {
+	rng    *rand.Rand
+	weight func(slotID string, mb *microBadge) float64
+}
+
+func (s *weightedStrategy) Name() string {
+	return "weighted"
+}
+
+func (s *weightedStrategy) Pick(slotID string, candidates []*microBadge) *microBadge {
+	if len(candidates) == 0 {
+		return nil
+	}
+	weights := make([]float64, len(candidates))
+	total := 0.0
+	for i, mb := range candidates {
+		if w := s.weight(slotID, mb); w > 0 {
+			weights[i] = w
+			total += w
+		}
+	}
+	if total <= 0 {
+		return candidates[s.rng.Intn(len(candidates))]
+	}
+	target := s.rng.Float64() * total
+	last := 0
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		if target < w {
+			return candidates[i]
+		}
+		target -= w
+		last = i
+	}
+	return candidates[last]
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func testCandidates(ids ...string) []*microBadge {
+	candidates := make([]*microBadge, len(ids))
+	for i, id := range ids {
+		candidates[i] = &microBadge{Id: id}
+	}
+	return candidates
+}
+
+// picks returns the ids the named strategy picks for slot 1 in n rounds.
+func picks(strategies map[string]selectionStrategy, name string, candidates []*microBadge, n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = strategies[name].Pick("1", candidates).Id
+	}
+	return ids
+}
+
+func TestStrategiesAreDeterministic(t *testing.T) {
+	candidates := testCandidates("1", "2", "3", "4", "5")
+	for _, name := range strategyNames {
+		first := picks(newStrategies(42), name, candidates, 50)
+		second := picks(newStrategies(42), name, candidates, 50)
+		if fmt.Sprint(first) != fmt.Sprint(second) {
+			t.Errorf("%s picked %v and then %v with the same seed", name, first, second)
+		}
+	}
+}
+
+func TestDefaultStrategy(t *testing.T) {
+	if name := getStrategy(newStrategies(1), "").Name(); name != "weighted" {
+		t.Errorf("slots without a strategy use %s, want weighted", name)
+	}
+}
+
+func TestStrategiesWithoutCandidates(t *testing.T) {
+	strategies := newStrategies(1)
+	for _, name := range strategyNames {
+		if mb := strategies[name].Pick("1", nil); mb != nil {
+			t.Errorf("%s picked %v from no candidates", name, mb)
+		}
+	}
+}
+
+// checkShares fails when the share of picks of any badge differs from want
+// by more than two percentage points.
+func checkShares(t *testing.T, name string, ids []string, want map[string]float64) {
+	t.Helper()
+	counts := map[string]int{}
+	for _, id := range ids {
+		counts[id]++
+	}
+	for id, share := range want {
+		got := float64(counts[id]) / float64(len(ids))
+		if math.Abs(got-share) > 0.02 {
+			t.Errorf("%s picked %s %.3f of the time, want %.3f", name, id, got, share)
+		}
+	}
+	for id := range counts {
+		if _, ok := want[id]; !ok {
+			t.Errorf("%s picked %s, which should never be picked", name, id)
+		}
+	}
+}
+
+func TestUniformStrategyDistribution(t *testing.T) {
+	ids := picks(newStrategies(7), "random", testCandidates("1", "2", "3", "4"), 20000)
+	checkShares(t, "random", ids, map[string]float64{"1": 0.25, "2": 0.25, "3": 0.25, "4": 0.25})
+}
+
+func TestWeightedStrategyDistribution(t *testing.T) {
+	candidates := testCandidates("1", "2", "3")
+	candidates[0].SetWeight(0, 1)
+	candidates[1].SetWeight(0, 3)
+	candidates[2].SetWeight(0, 0)
+	ids := picks(newStrategies(7), "weighted", candidates, 20000)
+	checkShares(t, "weighted", ids, map[string]float64{"1": 0.25, "2": 0.75})
+
+	// Weights only count in their own slot
+	other := newStrategies(7)["weighted"]
+	counts := map[string]int{}
+	for i := 0; i < 20000; i++ {
+		counts[other.Pick("2", candidates).Id]++
+	}
+	if counts["3"] == 0 {
+		t.Error("a weight of slot 1 kept the badge out of slot 2")
+	}
+
+	// Only zero weights: uniform
+	for _, mb := range candidates {
+		mb.SetWeight(0, 0)
+	}
+	ids = picks(newStrategies(7), "weighted", candidates, 20000)
+	checkShares(t, "weighted", ids, map[string]float64{"1": 1.0 / 3, "2": 1.0 / 3, "3": 1.0 / 3})
+}
+
+func TestRoundRobinStrategy(t *testing.T) {
+	strategies := newStrategies(1)
+	ids := picks(strategies, "round-robin", testCandidates("1", "2", "3"), 7)
+	if got, want := fmt.Sprint(ids), "[1 2 3 1 2 3 1]"; got != want {
+		t.Errorf("picked %s, want %s", got, want)
+	}
+	// Each slot keeps its own place, and a removed badge is skipped
+	if mb := strategies["round-robin"].Pick("2", testCandidates("1", "2", "3")); mb.Id != "1" {
+		t.Errorf("slot 2 started with %s, want 1", mb.Id)
+	}
+	if mb := strategies["round-robin"].Pick("1", testCandidates("1", "3")); mb.Id != "3" {
+		t.Errorf("slot 1 continued with %s, want 3", mb.Id)
+	}
+}
+
+func TestLeastRecentStrategy(t *testing.T) {
+	s := newStrategies(1)["least-recent"]
+	ids := []string{}
+	for _, candidates := range [][]*microBadge{
+		testCandidates("1", "2", "3"),
+		testCandidates("1", "2", "3"),
+		testCandidates("1", "3"),
+		testCandidates("1", "2", "3"),
+		testCandidates("1", "2", "3", "4"),
+	} {
+		ids = append(ids, s.Pick("1", candidates).Id)
+	}
+	if got, want := fmt.Sprint(ids), "[1 2 3 1 4]"; got != want {
+		t.Errorf("picked %s, want %s", got, want)
+	}
+}
+
+func TestSlotStrategyIsSaved(t *testing.T) {
+	a := newTestAccount(t, newFakeBGG(nil))
+	a.state.replaceBadges(map[string]*microBadge{"1": {Id: "1", Selected: []bool{false, true}}})
+	a.state.setSlotStrategy("2", "round-robin")
+	if err := a.submitCheckedMicroBadges(a.state.selectedSlots()); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, err := newAccount(defaultAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.store.Close()
+	restarted.loadSelections()
+	if got := restarted.state.slotStrategy("2"); got != "round-robin" {
+		t.Errorf("slot 2 uses %s after a restart, want round-robin", got)
+	}
+	if got := restarted.state.slotStrategy("1"); got != *strategy {
+		t.Errorf("slot 1 uses %s after a restart, want the default %s", got, *strategy)
+	}
+}
+
+func TestPresetCommandLoadsStrategies(t *testing.T) {
+	a := newTestAccount(t, newFakeBGG(nil))
+	a.state.replaceBadges(map[string]*microBadge{"1": {Id: "1", Selected: []bool{false, true}}})
+	a.state.setSlotStrategy("2", "round-robin")
+	if err := a.savePreset("rotation"); err != nil {
+		t.Fatal(err)
+	}
+	a.state.setSlotStrategy("2", "least-recent")
+
+	if code := presetCommand(a, []string{"load", "rotation"}); code != exitOK {
+		t.Fatalf("preset load exited with %d", code)
+	}
+	if got := a.state.slotStrategy("2"); got != "round-robin" {
+		t.Errorf("slot 2 uses %s after loading the preset, want round-robin", got)
+	}
+	if code := presetCommand(a, []string{"load", "missing"}); code != exitFailed {
+		t.Errorf("loading a missing preset exited with %d, want %d", code, exitFailed)
+	}
+}
@@ -1,121 +1,360 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"github.com/yhat/scrape"
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/atom"
-	"net/http"
+	"io"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-func getMicroBadges(client *http.Client) error {
-	resp, err := client.Get("https://boardgamegeek.com/user/" + *username + "/microbadges")
-	if err != nil {
-		return err
+var errNoMicrobadgeSection = errors.New("No microbadge section found on the profile page")
+
+var errNoSlotSection = errors.New("No microbadge slots found on the profile page")
+
+// parseError describes a part of the microbadge page that could not be
+// understood. Row is the 1-based row of the microbadge table and Badge the
+// badge id or link, when known.
+type parseError struct {
+	Row    int
+	Badge  string
+	Reason string
+}
+
+func (e *parseError) Error() string {
+	message := "Microbadge page"
+	if e.Row > 0 {
+		message += fmt.Sprintf(" row %d", e.Row)
+	}
+	if e.Badge != "" {
+		message += " badge " + e.Badge
 	}
+	return message + ": " + e.Reason
+}
 
-	root, err := html.Parse(resp.Body)
+// parseErrors collects every parseError of a page. The badges that did parse
+// are still returned alongside it.
+type parseErrors []*parseError
+
+func (e parseErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, v := range e {
+		messages[i] = v.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (a *account) getMicroBadges() error {
+	page, err := a.bgg.FetchMicrobadges(a.state.Username())
 	if err != nil {
 		return err
 	}
+	defer page.Close()
 
-	profileTitleBlocks := scrape.FindAllNested(root, scrape.ByClass("profile_title"))
-	for _, v := range profileTitleBlocks {
-		if strings.Contains(v.FirstChild.Data, "Microbadges") {
-			parseMicroBadgeData(v.NextSibling.NextSibling.FirstChild)
+	parsedBadges, err := parseMicroBadgePage(page, bggURL(""), a.state.SlotCount())
+	pageErrors, partial := err.(parseErrors)
+	if partial {
+		for _, v := range pageErrors {
+			a.state.notify(v.Error())
 		}
+		a.state.notify("Some microbadges could not be read, so none are marked as removed in this sync")
+	} else if err != nil {
+		return err
+	}
+	diff := a.state.mergeScraped(parsedBadges, partial)
+	if !diff.empty() {
+		a.state.notify(diff.String())
+		// Rebuild the slots so removed badges are no longer picked and
+		// returning badges are picked again. A failed save is notified but
+		// does not stop the randomization.
+		a.submitCheckedMicroBadges(a.state.selectedSlots())
 	}
-	// for k, v := range tmpMicroBadgeMap {
-	// 	if mb, ok := microBadgeMap[k]; ok {
-	// 		mb.UpdateMB(v)
-	// 	} else {
-	// 		microBadgeMap[k] = v
-	// 	}
-	// }
-	microBadgeMap = tmpMicroBadgeMap
-	categoryMap = getCategories()
 	return nil
 }
 
-func getCategories() map[string]mbSlice {
-	tmpCategoryMap := make(map[string]mbSlice)
-	for _, v := range microBadgeMap {
-		tmpCategoryMap[v.Category] = append(tmpCategoryMap[v.Category], v)
+// maxDiffNames limits how many badge names a sync notification lists per
+// kind of change.
+const maxDiffNames = 10
+
+// syncDiff lists the names of the badges a sync added, removed or changed.
+type syncDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+func (d syncDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d syncDiff) String() string {
+	message := "Microbadges synced:"
+	for _, part := range []struct {
+		label string
+		names []string
+	}{{"added", d.Added}, {"removed", d.Removed}, {"changed", d.Changed}} {
+		if len(part.names) == 0 {
+			continue
+		}
+		sort.Strings(part.names)
+		names := part.names
+		if len(names) > maxDiffNames {
+			names = append(names[:maxDiffNames:maxDiffNames], fmt.Sprintf("and %d more", len(part.names)-maxDiffNames))
+		}
+		message += fmt.Sprintf(" %d %s (%s)", len(part.names), part.label, strings.Join(names, ", "))
 	}
+	return message
+}
 
-	for _, v := range tmpCategoryMap {
-		mbSort(v)
+// mergeMicroBadges folds freshly scraped badges into current. New badges are
+// added, known ones get their metadata updated while keeping the user's
+// selections and weights, and badges missing from the scrape are marked
+// Removed rather than deleted. A partial scrape, with rows that failed to
+// parse, removes nothing, as the missing badges may be in those rows.
+func mergeMicroBadges(current map[string]*microBadge, scraped []*microBadge, partial bool) syncDiff {
+	var diff syncDiff
+	seen := map[string]bool{}
+	for _, v := range scraped {
+		seen[v.Id] = true
+		existingMB, ok := current[v.Id]
+		if !ok {
+			current[v.Id] = v
+			diff.Added = append(diff.Added, badgeLabel(v))
+			continue
+		}
+		if existingMB.Removed {
+			existingMB.Removed = false
+			existingMB.UpdateMB(v)
+			diff.Added = append(diff.Added, badgeLabel(existingMB))
+			continue
+		}
+		if !existingMB.sameMetadata(v) {
+			existingMB.UpdateMB(v)
+			diff.Changed = append(diff.Changed, badgeLabel(existingMB))
+		}
+	}
+	if partial {
+		return diff
+	}
+	for id, v := range current {
+		if !seen[id] && !v.Removed {
+			v.Removed = true
+			diff.Removed = append(diff.Removed, badgeLabel(v))
+		}
 	}
-	return tmpCategoryMap
+	return diff
 }
 
-func parseMicroBadgeData(node *html.Node) {
+func badgeLabel(mb *microBadge) string {
+	if mb.Name != "" {
+		return mb.Name
+	}
+	return mb.Id
+}
 
-	badgeRows := scrape.FindAllNested(node, scrape.ByTag(atom.Tr))
-	categories := []string{}
-	for _, v := range badgeRows {
-		categoryTdNode := v.FirstChild.NextSibling
-		currentCategory := categoryTdNode.FirstChild.NextSibling.FirstChild.Data
-		categories = append(categories, currentCategory)
+// parseMicroBadgePage reads a user's microbadge page and returns its badges
+// sorted by Id. Elements are found by their attributes rather than their
+// position, so extra wrappers or whitespace in the markup do not matter. Rows
+// or badges that cannot be read are reported as parseErrors while the rest of
+// the page is still returned. Relative badge links are resolved against
+// baseURL, and new badges can be selected for slotCount slots.
+func parseMicroBadgePage(page io.Reader, baseURL string, slotCount int) ([]*microBadge, error) {
+	root, err := html.Parse(page)
+	if err != nil {
+		return nil, err
+	}
+	section, ok := findMicroBadgeSection(root)
+	if !ok {
+		return nil, errNoMicrobadgeSection
+	}
 
-		getBadgesInCategory(currentCategory, v)
+	badges := map[string]*microBadge{}
+	var pageErrors parseErrors
+	for i, row := range scrape.FindAllNested(section, scrape.ByTag(atom.Tr)) {
+		pageErrors = append(pageErrors, parseMicroBadgeRow(i+1, row, baseURL, slotCount, badges)...)
 	}
 
-	return
+	badgeList := make([]*microBadge, 0, len(badges))
+	for _, v := range badges {
+		badgeList = append(badgeList, v)
+	}
+	sort.Slice(badgeList, func(i, j int) bool {
+		return badgeList[i].Id < badgeList[j].Id
+	})
+	if len(pageErrors) > 0 {
+		return badgeList, pageErrors
+	}
+	return badgeList, nil
 }
 
-func getBadgesInCategory(category string, node *html.Node) {
-	microbadges := scrape.FindAllNested(node, scrape.ByTag(atom.A))
-	for _, mbAnchor := range microbadges {
-		mb := mbAnchor.Attr[0].Val
-		if strings.Contains(mb, "/microbadge/") {
-			mb = strings.Trim(mb, "/")
-			mb = strings.Split(mb, "/")[1]
-			if existingMB, ok := tmpMicroBadgeMap[mb]; ok {
-				existingMB.Category = category
-			} else {
-				tmpMicroBadgeMap[mb] = &microBadge{Id: mb, Category: category, Selected: make([]bool, 5)}
+// findMicroBadgeSection returns the first element following the
+// "Microbadges" profile title.
+func findMicroBadgeSection(root *html.Node) (*html.Node, bool) {
+	for _, title := range scrape.FindAllNested(root, scrape.ByClass("profile_title")) {
+		if !strings.Contains(scrape.Text(title), "Microbadges") {
+			continue
+		}
+		for sibling := title.NextSibling; sibling != nil; sibling = sibling.NextSibling {
+			if sibling.Type == html.ElementNode {
+				return sibling, true
 			}
 		}
 	}
+	return nil, false
+}
 
-	mbImages := scrape.FindAllNested(node, scrape.ByTag(atom.Img))
-	for _, mbImg := range mbImages {
-		for _, v := range mbImg.Attr {
-			switch v.Key {
-			case "class":
-				if v.Val == "tilebadge" {
-					//TODO get tilebadge img url here
-				}
-			case "data-frz-src":
-				imgLink := v.Val
-				mb := strings.Split(imgLink, "_")[1]
-				if existingMB, ok := tmpMicroBadgeMap[mb]; ok {
-					existingMB.ImgURL = imgLink
-				} else {
-					tmpMicroBadgeMap[mb] = &microBadge{Id: mb, ImgURL: imgLink, Category: category, Selected: make([]bool, 5)}
-				}
-			case "onmouseover":
-				mbDescription := v.Val
-				mbDescription = strings.TrimPrefix(mbDescription, "return overlib('")
-				mbDescription = strings.TrimSuffix(mbDescription, "', WRAP );")
-				mbDescription = strings.Replace(mbDescription, "\\'", "'", -1)
-
-				mb := mbImg.Parent.Attr[0].Val
-				if strings.Contains(mb, "/microbadge/") {
-					mb = strings.Trim(mb, "/")
-					mb = strings.Split(mb, "/")[1]
-					if existingMB, ok := tmpMicroBadgeMap[mb]; ok {
-						existingMB.Description = mbDescription
-					} else {
-						tmpMicroBadgeMap[mb] = &microBadge{Id: mb, Name: mbDescription, Selected: make([]bool, 5)}
-					}
-
-				}
+// parseAssignedSlots reads which badge each slot shows from a profile page.
+// The slots are the child elements of the profile_microbadges element, in
+// order, and a slot without a badge link is empty.
+func parseAssignedSlots(page io.Reader) (map[string]string, error) {
+	root, err := html.Parse(page)
+	if err != nil {
+		return nil, err
+	}
+	section, ok := scrape.Find(root, scrape.ByClass("profile_microbadges"))
+	if !ok {
+		return nil, errNoSlotSection
+	}
+	slots := map[string]string{}
+	for slot := section.FirstChild; slot != nil; slot = slot.NextSibling {
+		if slot.Type != html.ElementNode {
+			continue
+		}
+		slotID := strconv.Itoa(len(slots) + 1)
+		slots[slotID] = ""
+		if link, ok := scrape.Find(slot, isMicroBadgeLink); ok {
+			slots[slotID] = badgeIDFromLink(scrape.Attr(link, "href"))
+			if slots[slotID] == "" {
+				return nil, errors.New("Profile page slot " + slotID + ": link has no badge id")
+			}
+		}
+	}
+	return slots, nil
+}
+
+func isMicroBadgeLink(node *html.Node) bool {
+	return node.DataAtom == atom.A && strings.Contains(scrape.Attr(node, "href"), "/microbadge/")
+}
+
+// parseMicroBadgeRow adds the badges of one category row to badges. Rows
+// without any badge links, such as headers, are skipped.
+func parseMicroBadgeRow(rowNumber int, row *html.Node, baseURL string, slotCount int, badges map[string]*microBadge) parseErrors {
+	links := scrape.FindAllNested(row, isMicroBadgeLink)
+	if len(links) == 0 {
+		return nil
+	}
+	var rowErrors parseErrors
+
+	category := ""
+	for _, cell := range scrape.FindAll(row, scrape.ByTag(atom.Td)) {
+		if _, hasBadges := scrape.Find(cell, isMicroBadgeLink); !hasBadges {
+			category = scrape.Text(cell)
+			break
+		}
+	}
+	if category == "" {
+		category = "Uncategorized"
+		rowErrors = append(rowErrors, &parseError{Row: rowNumber, Reason: "no category cell"})
+	}
+
+	for _, link := range links {
+		href := scrape.Attr(link, "href")
+		id := badgeIDFromLink(href)
+		if id == "" {
+			rowErrors = append(rowErrors, &parseError{Row: rowNumber, Badge: href, Reason: "link has no badge id"})
+			continue
+		}
+		mb, ok := badges[id]
+		if !ok {
+			mb = &microBadge{Id: id, Category: category, Selected: make([]bool, slotCount)}
+			badges[id] = mb
+		}
+		mb.PageURL = href
+		if strings.HasPrefix(href, "/") {
+			mb.PageURL = strings.TrimSuffix(baseURL, "/") + href
+		}
+		for _, img := range scrape.FindAllNested(link, scrape.ByTag(atom.Img)) {
+			if hasClass(img, "tilebadge") {
+				mb.TileImgURL = badgeImageURL(img)
+				continue
+			}
+			mb.ImgURL = badgeImageURL(img)
+			if tooltip := overlibText(scrape.Attr(img, "onmouseover")); tooltip != "" {
+				mb.Description = tooltip
 			}
+			if mb.Name == "" {
+				mb.Name = scrape.Attr(img, "alt")
+			}
+		}
+		if mb.ImgURL == "" && mb.TileImgURL == "" {
+			rowErrors = append(rowErrors, &parseError{Row: rowNumber, Badge: id, Reason: "no badge image"})
+		}
+		if title := scrape.Attr(link, "title"); title != "" {
+			mb.Name = title
+		}
+		if mb.Name == "" {
+			mb.Name = mb.Description
+		}
+		if mb.Name == "" {
+			mb.Name = scrape.Text(link)
+		}
+	}
+	return rowErrors
+}
 
+func hasClass(node *html.Node, class string) bool {
+	for _, v := range strings.Fields(scrape.Attr(node, "class")) {
+		if v == class {
+			return true
 		}
+	}
+	return false
+}
 
+// badgeIDFromLink returns the path segment after /microbadge/ in a badge
+// link, or an empty string when it is not a number.
+func badgeIDFromLink(href string) string {
+	id := href[strings.Index(href, "/microbadge/")+len("/microbadge/"):]
+	id = strings.SplitN(id, "/", 2)[0]
+	id = strings.SplitN(id, "?", 2)[0]
+	if id == "" || strings.Trim(id, "0123456789") != "" {
+		return ""
 	}
+	return id
+}
+
+// badgeImageURL prefers the lazy loaded image over the placeholder in src.
+func badgeImageURL(img *html.Node) string {
+	if src := scrape.Attr(img, "data-frz-src"); src != "" {
+		return src
+	}
+	src := scrape.Attr(img, "src")
+	if path.Base(src) == "blank.gif" {
+		return ""
+	}
+	return src
+}
 
+// overlibText returns the tooltip text of an onmouseover="return overlib(...)"
+// handler.
+func overlibText(handler string) string {
+	text := strings.TrimSpace(handler)
+	text = strings.TrimPrefix(text, "return ")
+	text = strings.TrimPrefix(text, "overlib(")
+	text = strings.TrimSuffix(text, ";")
+	text = strings.TrimSpace(text)
+	text = strings.TrimSuffix(text, ")")
+	text = strings.TrimSpace(text)
+	text = strings.TrimSuffix(text, "WRAP")
+	text = strings.TrimSpace(text)
+	text = strings.TrimSuffix(text, ",")
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "'")
+	text = strings.TrimSuffix(text, "'")
+	return strings.Replace(text, "\\'", "'", -1)
 }
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files in testdata with the current output")
+
+// parseResult is what a golden file records about parsing a page.
+type parseResult struct {
+	Badges []*microBadge
+	Errors []string
+}
+
+func parsePageFile(t *testing.T, file string) parseResult {
+	t.Helper()
+	page, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer page.Close()
+	badges, err := parseMicroBadgePage(page, "https://boardgamegeek.com", defaultSlotCount)
+	result := parseResult{Badges: badges}
+	if pageErrors, ok := err.(parseErrors); ok {
+		for _, v := range pageErrors {
+			result.Errors = append(result.Errors, v.Error())
+		}
+	} else if err != nil {
+		result.Errors = []string{err.Error()}
+	}
+	return result
+}
+
+// TestParseMicroBadgePageGolden parses every page in testdata/microbadges
+// and compares the result with its golden file. Run the tests with -update
+// to rewrite the golden files after an intended change.
+func TestParseMicroBadgePageGolden(t *testing.T) {
+	pages, err := filepath.Glob(filepath.Join("testdata", "microbadges", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) == 0 {
+		t.Fatal("no pages in testdata/microbadges")
+	}
+	for _, page := range pages {
+		t.Run(filepath.Base(page), func(t *testing.T) {
+			got, err := json.MarshalIndent(parsePageFile(t, page), "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+			golden := strings.TrimSuffix(page, ".html") + ".golden.json"
+			if *update {
+				if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("parsing %s no longer matches %s, run the tests with -update if that is intended:\n%s", page, golden, got)
+			}
+		})
+	}
+}
+
+func TestMergeMicroBadges(t *testing.T) {
+	current := map[string]*microBadge{
+		"1": {Id: "1", Name: "Old name", Selected: []bool{true}, Weights: []float64{2}},
+		"2": {Id: "2", Name: "Returning", Selected: []bool{false, true}, Removed: true},
+		"3": {Id: "3", Name: "Leaving", Selected: []bool{true}},
+	}
+	scraped := []*microBadge{
+		{Id: "1", Name: "New name"},
+		{Id: "2", Name: "Returning"},
+		{Id: "4", Name: "New"},
+	}
+	diff := mergeMicroBadges(current, scraped, false)
+	sort.Strings(diff.Added)
+	if got, want := fmt.Sprint(diff.Added, diff.Removed, diff.Changed), "[New Returning] [Leaving] [New name]"; got != want {
+		t.Errorf("added, removed and changed %s, want %s", got, want)
+	}
+	if mb := current["1"]; mb.Name != "New name" || !mb.IsSelected(0) || mb.Weight(0) != 2 {
+		t.Errorf("badge 1 is %+v, want the new name with its selection and weight", mb)
+	}
+	if mb := current["2"]; mb.Removed || !mb.IsSelected(1) {
+		t.Errorf("badge 2 is %+v, want it back with its selection", mb)
+	}
+	if mb := current["3"]; !mb.Removed || !mb.IsSelected(0) {
+		t.Errorf("badge 3 is %+v, want it removed but still selected", mb)
+	}
+	if current["4"] == nil {
+		t.Error("badge 4 was not added")
+	}
+
+	if diff := mergeMicroBadges(current, scraped, false); !diff.empty() {
+		t.Errorf("merging the same scrape again changed %s", diff)
+	}
+}
+
+func TestMergePartialScrape(t *testing.T) {
+	current := map[string]*microBadge{
+		"1": {Id: "1", Name: "Kept"},
+		"2": {Id: "2", Name: "Unreadable", Selected: []bool{true}},
+	}
+	diff := mergeMicroBadges(current, []*microBadge{{Id: "1", Name: "Kept"}, {Id: "3", Name: "New"}}, true)
+	if len(diff.Removed) != 0 || len(diff.Added) != 1 {
+		t.Errorf("a partial scrape added %v and removed %v", diff.Added, diff.Removed)
+	}
+	if current["2"].Removed {
+		t.Error("a badge missing from a partial scrape was marked removed")
+	}
+}
+
+// syncNotifications returns the sync summaries a has been notified of.
+func syncNotifications(a *account) []string {
+	var synced []string
+	for _, message := range a.state.notificationList() {
+		if strings.Contains(message, "Microbadges synced") {
+			synced = append(synced, message)
+		}
+	}
+	return synced
+}
+
+func TestSyncAfterRestart(t *testing.T) {
+	page, err := ioutil.ReadFile("testdata/microbadges/profile.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeBGG(page)
+	a := newTestAccount(t, fake)
+	if err := a.loginWith(staticCredentials{name: "the test", login: credentials{"user", "pw"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.getMicroBadges(); err != nil {
+		t.Fatal(err)
+	}
+	if len(syncNotifications(a)) != 1 {
+		t.Fatalf("the first sync notified %q", syncNotifications(a))
+	}
+	saved, err := a.store.LoadSelections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Badges) != a.state.badgeCount() {
+		t.Errorf("saved %d of %d badges although none is selected", len(saved.Badges), a.state.badgeCount())
+	}
+
+	restarted := reopenAccount(t, fake)
+	restarted.loadSelections()
+	if err := restarted.getMicroBadges(); err != nil {
+		t.Fatal(err)
+	}
+	if synced := syncNotifications(restarted); len(synced) != 0 {
+		t.Errorf("syncing an unchanged profile after a restart notified %q", synced)
+	}
+}
+
+func TestParseAssignedSlots(t *testing.T) {
+	page := `<html><body><div class="profile_microbadges">
+<span class="mb_slot"><a href="/microbadge/1001"><img class="mb" /></a></span>
+<span class="mb_slot"></span>
+<span class="mb_slot"><a href="https://boardgamegeek.com/microbadge/2002?x=1"><img class="mb" /></a></span>
+</div></body></html>`
+	slots, err := parseAssignedSlots(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(slots), "map[1:1001 2: 3:2002]"; got != want {
+		t.Errorf("read the slots %s, want %s", got, want)
+	}
+
+	if _, err := parseAssignedSlots(strings.NewReader(`<html><body>No slots</body></html>`)); err != errNoSlotSection {
+		t.Errorf("a page without slots gave %v, want errNoSlotSection", err)
+	}
+	broken := `<div class="profile_microbadges"><span><a href="/microbadge/abc">x</a></span></div>`
+	if _, err := parseAssignedSlots(strings.NewReader(broken)); err == nil {
+		t.Error("a slot link without a badge id was accepted")
+	}
+}
@@ -9,19 +9,27 @@ import (
 	"syscall"
 )
 
-func getUsername() {
-	fmt.Print("Username: ")
-	reader := bufio.NewReader(os.Stdin)
-	providedUsername, err := reader.ReadBytes('\n')
-	if err != nil {
-		return
+// promptCredentials asks for the login on the terminal, or only for the
+// password when username is known.
+func promptCredentials(username string) (credentials, bool) {
+	if !terminal.IsTerminal(int(syscall.Stdin)) {
+		return credentials{}, false
+	}
+	login := credentials{Username: username}
+	if login.Username == "" {
+		fmt.Print("Username: ")
+		reader := bufio.NewReader(os.Stdin)
+		providedUsername, err := reader.ReadBytes('\n')
+		if err != nil {
+			return credentials{}, false
+		}
+		login.Username = strings.Trim(string(providedUsername), "\n\r")
 	}
-	fmt.Print("Password: ")
-	providedPassword, err := terminal.ReadPassword(int(syscall.Stdin))
+	providedPassword, err := readSecret("Password: ")
 	if err != nil {
-		return
+		return credentials{}, false
 	}
-	*username = strings.Trim(string(providedUsername), "\n\r")
-	*password = strings.Trim(string(providedPassword), "\n\r")
-	fmt.Println()
+	login.Password = providedPassword
+	rememberSecret(login.Password)
+	return login, login.Username != "" && login.Password != ""
 }